@@ -0,0 +1,199 @@
+package timers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An Attr is a typed key/value attribute on a Timer. Unlike tags, attributes keep their type,
+// so a status code stays an int and a size stays an int when exported and imported again.
+//
+// Value is always one of string, int64, uint64, float64, bool or time.Duration.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// The attribute types, as written to JSON.
+const (
+	attrString   = "string"
+	attrInt      = "int"
+	attrUint     = "uint"
+	attrFloat    = "float"
+	attrBool     = "bool"
+	attrDuration = "duration"
+)
+
+// Sets an attribute on the timer. Setting an attribute that already exists replaces its value.
+// The value can be a string, bool, time.Duration, or any int or float type. Ints are stored as
+// int64, apart from unsigned ints too large for an int64, which are stored as uint64, and floats
+// are stored as float64. Any other type is stored as a string using fmt.Sprint.
+//
+// Like Tag(), it can be chained:
+//  timers.From(ctx).New("db query").Attr("rows", len(rows)).Attr("cached", false)
+func (t *Timer) Attr(key string, value interface{}) *Timer {
+	if t.readOnly() {
+		return t
+	}
+	value = attrValue(value)
+	t.lock()
+	defer t.unlock()
+	for i := range t.attrs {
+		if t.attrs[i].Key == key {
+			t.attrs[i].Value = value
+			return t
+		}
+	}
+	t.attrs = append(t.attrs, Attr{Key: key, Value: value})
+	return t
+}
+
+// Returns a copy of the timer's attributes, in the order they were first set.
+func (t *Timer) Attrs() []Attr {
+	t.lock()
+	defer t.unlock()
+	attrs := make([]Attr, len(t.attrs))
+	copy(attrs, t.attrs)
+	return attrs
+}
+
+// Normalises a value to one of the supported attribute types.
+func attrValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, bool, int64, float64, time.Duration:
+		return v
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return uintValue(uint64(v))
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return uintValue(v)
+	case uintptr:
+		return uintValue(uint64(v))
+	case float32:
+		return float64(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Returns v as an int64, or as a uint64 if it is too large for one.
+func uintValue(v uint64) interface{} {
+	if v > math.MaxInt64 {
+		return v
+	}
+	return int64(v)
+}
+
+// Returns the attribute as key=value
+func (a Attr) String() string {
+	switch v := a.Value.(type) {
+	case string:
+		return a.Key + "=" + v
+	case float64:
+		return a.Key + "=" + strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprintf("%s=%v", a.Key, v)
+	}
+}
+
+// Formats a list of attributes for Timer.String()
+func fmtAttrs(attrs []Attr) string {
+	str := make([]string, len(attrs))
+	for i, a := range attrs {
+		str[i] = a.String()
+	}
+	return strings.Join(str, ",")
+}
+
+// Marshaling type. The type is recorded so that ints, floats and durations survive a round trip.
+// Floats that JSON can't represent (NaN and the infinities) are written as strings.
+type marshalAttr struct {
+	Key   string          `json:"key"`
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (a Attr) toMarshalAttr() (marshalAttr, error) {
+	var typ string
+	var value interface{} = a.Value
+	switch v := a.Value.(type) {
+	case int64:
+		typ = attrInt
+	case uint64:
+		typ = attrUint
+	case float64:
+		typ = attrFloat
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			value = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	case bool:
+		typ = attrBool
+	case time.Duration:
+		typ = attrDuration
+		value = int64(v)
+	default:
+		typ = attrString
+		value = fmt.Sprint(v)
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return marshalAttr{}, fmt.Errorf("timer attribute %s: %w", a.Key, err)
+	}
+	return marshalAttr{Key: a.Key, Type: typ, Value: bytes}, nil
+}
+
+func (ma marshalAttr) toAttr() (Attr, error) {
+	var err error
+	a := Attr{Key: ma.Key}
+	switch ma.Type {
+	case attrInt:
+		var v int64
+		err = json.Unmarshal(ma.Value, &v)
+		a.Value = v
+	case attrUint:
+		var v uint64
+		err = json.Unmarshal(ma.Value, &v)
+		a.Value = v
+	case attrFloat:
+		var v float64
+		var str string
+		if json.Unmarshal(ma.Value, &str) == nil {
+			v, err = strconv.ParseFloat(str, 64)
+		} else {
+			err = json.Unmarshal(ma.Value, &v)
+		}
+		a.Value = v
+	case attrBool:
+		var v bool
+		err = json.Unmarshal(ma.Value, &v)
+		a.Value = v
+	case attrDuration:
+		var v int64
+		err = json.Unmarshal(ma.Value, &v)
+		a.Value = time.Duration(v)
+	case attrString, "":
+		var v string
+		err = json.Unmarshal(ma.Value, &v)
+		a.Value = v
+	default:
+		err = fmt.Errorf("timer attribute %s has unknown type %s", ma.Key, ma.Type)
+	}
+	return a, err
+}
@@ -0,0 +1,113 @@
+package timers
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttr(t *testing.T) {
+	timer := newSet().New("attrs")
+	timer.Attr("status", 503).Attr("ratio", float32(0.5)).Attr("cached", true)
+	timer.Attr("took", 1500*time.Microsecond).Attr("host", "db1").Attr("status", 200)
+
+	attrs := timer.Attrs()
+	if len(attrs) != 5 {
+		t.Fatalf("Expected 5 attributes, got %d", len(attrs))
+	}
+	expect := []Attr{
+		{"status", int64(200)},
+		{"ratio", float64(0.5)},
+		{"cached", true},
+		{"took", 1500 * time.Microsecond},
+		{"host", "db1"},
+	}
+	for i := range expect {
+		if attrs[i] != expect[i] {
+			t.Errorf("Attribute %d was %#v, expected %#v", i, attrs[i], expect[i])
+		}
+	}
+
+	str := timer.copy().String()
+	if !strings.Contains(str, "attrs:(status=200,ratio=0.5,cached=true,took=1.5ms,host=db1)") {
+		t.Errorf("String() did not contain attributes: %s", str)
+	}
+}
+
+func TestAttrUnknownType(t *testing.T) {
+	type thing struct{ a int }
+	timer := newSet().New("attrs").Attr("thing", thing{4})
+	if timer.Attrs()[0].Value != "{4}" {
+		t.Errorf("Unknown type was not stored as a string: %#v", timer.Attrs()[0].Value)
+	}
+}
+
+func TestAttrMarshalling(t *testing.T) {
+	s := newSet()
+	s.New("parent").Attr("rows", 1200).Attr("size", 3.25).Attr("ok", false).
+		Attr("wait", 2*time.Second).Attr("table", "users").Start().Stop()
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(string(bytes))
+	if !strings.Contains(string(bytes), `{"key":"rows","type":"int","value":1200}`) {
+		t.Error("JSON did not contain typed rows attribute")
+	}
+
+	var s2 TimerSet
+	if err := json.Unmarshal(bytes, &s2); err != nil {
+		t.Fatal(err)
+	}
+	got := s2.All()[0].Attrs()
+	want := s.All()[0].Attrs()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d attributes after round trip, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Attribute %d was %#v after round trip, expected %#v", i, got[i], want[i])
+		}
+	}
+
+	bad := `[{"name":"x","start":0,"duration":0,"attrs":[{"key":"k","type":"complex","value":1}]}]`
+	if err := json.Unmarshal([]byte(bad), &s2); err == nil {
+		t.Error("Unknown attribute type did not fail to unmarshal")
+	}
+}
+
+func TestAttrLimits(t *testing.T) {
+	s := newSet()
+	s.New("limits").Attr("max", uint64(math.MaxUint64)).Attr("small", uint(7)).Attr("nan", math.NaN()).
+		Attr("inf", math.Inf(1)).Attr("-inf", math.Inf(-1)).Start().Stop()
+	if v := s.Find("limits").Attrs()[0].Value; v != uint64(math.MaxUint64) {
+		t.Errorf("Large unsigned int was stored as %#v", v)
+	}
+	if v := s.Find("limits").Attrs()[1].Value; v != int64(7) {
+		t.Errorf("Small unsigned int was stored as %#v", v)
+	}
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bytes), `{"key":"nan","type":"float","value":"NaN"}`) {
+		t.Errorf("JSON did not contain the NaN as a string: %s", bytes)
+	}
+
+	var s2 TimerSet
+	if err := json.Unmarshal(bytes, &s2); err != nil {
+		t.Fatal(err)
+	}
+	attrs := s2.Find("limits").Attrs()
+	if attrs[0].Value != uint64(math.MaxUint64) || attrs[1].Value != int64(7) {
+		t.Errorf("Unsigned ints were %#v and %#v after round trip", attrs[0].Value, attrs[1].Value)
+	}
+	if v, ok := attrs[2].Value.(float64); !ok || !math.IsNaN(v) {
+		t.Errorf("NaN was %#v after round trip", attrs[2].Value)
+	}
+	if attrs[3].Value != math.Inf(1) || attrs[4].Value != math.Inf(-1) {
+		t.Errorf("Infinities were %#v and %#v after round trip", attrs[3].Value, attrs[4].Value)
+	}
+}
@@ -0,0 +1,93 @@
+package timers
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sets a latency budget for the timer. If the timer runs for longer than d, Stop() marks it as
+// over budget and calls the TimerSet's OnOverBudget function. A budget of zero removes it.
+//  defer timers.From(ctx).New("inventory lookup").Budget(50 * time.Millisecond).Start().Stop()
+func (t *Timer) Budget(d time.Duration) *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
+	t.budget = d
+	return t
+}
+
+// Returns true if the timer was stopped after running for longer than its budget.
+func (t *Timer) OverBudget() bool {
+	t.lock()
+	defer t.unlock()
+	return t.overBudget
+}
+
+// Sets the budget for every timer named name created in this TimerSet after this call, and in
+// TimerSets created from it with NewContext, NewContextWithTimer and Wrap. Timers can still
+// change their budget with Timer.Budget(). A budget of zero removes it.
+//  timers.From(ctx).SetBudget("inventory lookup", 50*time.Millisecond)
+func (s *TimerSet) SetBudget(name string, d time.Duration) *TimerSet {
+	if s.readOnly() {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The map is shared with child TimerSets, so it is replaced rather than changed
+	budgets := make(map[string]time.Duration, len(s.budgets)+1)
+	for k, v := range s.budgets {
+		budgets[k] = v
+	}
+	if d == 0 {
+		delete(budgets, name)
+	} else {
+		budgets[name] = d
+	}
+	s.budgets = budgets
+	return s
+}
+
+// Sets the function called when a timer is stopped over its budget, for timers created in this
+// TimerSet after this call, and in TimerSets created from it with NewContext,
+// NewContextWithTimer and Wrap. The function is given a copy of the timer, and is called from the
+// go routine that stopped it. A nil function removes it.
+//  timers.From(ctx).OnOverBudget(func(t timers.Timer) {
+//      log.Printf("%s was over budget", t)
+//  })
+func (s *TimerSet) OnOverBudget(fn func(Timer)) *TimerSet {
+	if s.readOnly() {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onOverBudget = fn
+	return s
+}
+
+// Sets the budget and callback of a timer being added to the set. Caller must hold the lock.
+func (s *TimerSet) applyBudget(t *Timer) {
+	if d, ok := s.budgets[t.name]; ok {
+		t.budget = d
+	}
+	t.onOverBudget = s.onOverBudget
+}
+
+// Marks a stopped timer as over budget if it is, and returns the function to call, if any.
+// Caller must hold the lock.
+func (t *Timer) checkBudget() func(Timer) {
+	if t.budget == 0 || t.duration <= t.budget {
+		return nil
+	}
+	t.overBudget = true
+	return t.onOverBudget
+}
+
+// Formats the budget for Timer.String()
+func fmtBudget(t *Timer) string {
+	if t.overBudget {
+		return fmt.Sprintf(" over budget:(%.3fms)", durationMs(t.budget))
+	}
+	return fmt.Sprintf(" budget:(%.3fms)", durationMs(t.budget))
+}
@@ -0,0 +1,99 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	var over []Timer
+	s := newSet().SetClock(clock).OnOverBudget(func(t Timer) {
+		over = append(over, t)
+	})
+	fast := s.New("fast").Budget(5 * time.Millisecond).Start()
+	slow := s.New("slow").Budget(5 * time.Millisecond).Start()
+	none := s.New("no budget").Start()
+	clock.Advance(5 * time.Millisecond)
+	fast.Stop()
+	clock.Advance(time.Millisecond)
+	slow.Stop().Stop()
+	none.Stop()
+
+	if fast.OverBudget() || !slow.OverBudget() || none.OverBudget() {
+		t.Error("OverBudget() is wrong")
+	}
+	if len(over) != 1 || over[0].name != "slow" || !over[0].overBudget || over[0].Duration() != 6*time.Millisecond {
+		t.Errorf("Over budget function was called with %v", over)
+	}
+	if str := slow.copy().String(); str != "slow: 6.000ms over budget:(5.000ms)" {
+		t.Errorf("String() was %q", str)
+	}
+	if str := fast.copy().String(); str != "fast: 5.000ms budget:(5.000ms)" {
+		t.Errorf("String() was %q", str)
+	}
+}
+
+func TestSetBudget(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	calls := 0
+	ctx := NewContext(context.Background())
+	From(ctx).SetClock(clock).SetBudget("lookup", time.Millisecond).OnOverBudget(func(Timer) { calls++ })
+	before := From(ctx).New("lookup")
+	child := NewContext(ctx)
+	From(ctx).SetBudget("lookup", 0).SetBudget("other", time.Millisecond)
+
+	timer := From(child).New("lookup").Start()
+	From(child).New("other").Start()
+	clock.Advance(2 * time.Millisecond)
+	timer.Stop()
+	From(child).Find("other").Stop()
+	if !timer.OverBudget() || calls != 1 {
+		t.Error("Child TimerSet didn't inherit the budget and function")
+	}
+	if From(child).Find("other").OverBudget() {
+		t.Error("Budget set after the child TimerSet was created was applied to it")
+	}
+	if before.budget != time.Millisecond || From(ctx).New("lookup").budget != 0 {
+		t.Error("Removing a budget didn't apply to new timers only")
+	}
+	if From(ctx).New("other").Budget(0).budget != 0 {
+		t.Error("Timer didn't override the TimerSet's budget")
+	}
+}
+
+func TestBudgetExported(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	timer := s.New("slow").Budget(1500 * time.Microsecond).Start()
+	clock.Advance(2 * time.Millisecond)
+	timer.Stop()
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"schema":2,"time":1644884400002000000,"timers":[{"id":1,"parent":0,"name":"slow","state":"stopped",` +
+		`"start":1644884400000000000,"duration":2000000,"budget":1500000,"overBudget":true}]}`
+	if string(b) != expect {
+		t.Errorf("JSON was\n%s\nexpected\n%s", b, expect)
+	}
+	var imported TimerSet
+	if err := json.Unmarshal(b, &imported); err != nil {
+		t.Fatal(err)
+	}
+	if i := imported.Find("slow"); !i.OverBudget() || i.budget != timer.budget {
+		t.Errorf("Imported timer was %s", i.copy())
+	}
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.HasSuffix(header, ";budget=1.500;over_budget=1") {
+		t.Errorf("Header doesn't include the budget: %s", header)
+	}
+}
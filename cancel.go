@@ -0,0 +1,52 @@
+package timers
+
+import "context"
+
+// Stops the running timers in this TimerSet and its children when ctx is done, such as when the
+// client disconnects or a deadline passes. Each timer is stopped at the time of cancellation,
+// rather than when the timers are later flushed with StopAllTimers, and fails with ctx.Err()
+// (context.Canceled or context.DeadlineExceeded), which is also added as a tag. Timers started
+// after ctx is done are not stopped.
+//
+// This waits on ctx in a go routine, which only exits once ctx is done, so ctx must be one that
+// will be cancelled, such as a request's context. A ctx that can never be done is ignored.
+//  ctx = timers.NewContext(r.Context())
+//  timers.From(ctx).StopOnDone(ctx)
+func (s *TimerSet) StopOnDone(ctx context.Context) *TimerSet {
+	if s.readOnly() || ctx.Done() == nil {
+		return s
+	}
+	go func() {
+		<-ctx.Done()
+		s.stopCanceled(ctx.Err())
+	}()
+	return s
+}
+
+// Stops all the running timers in the tree, failing them with err.
+func (s *TimerSet) stopCanceled(err error) {
+	s.mu.Lock()
+	timers := append([]*Timer(nil), s.timers...)
+	s.mu.Unlock()
+	for _, t := range timers {
+		t.cancel(err)
+		if sub := t.sub(); sub != nil {
+			sub.stopCanceled(err)
+		}
+	}
+}
+
+// Stops the timer if it is running, failing it with err and tagging it.
+func (t *Timer) cancel(err error) {
+	t.lock()
+	if !t.running() {
+		t.unlock()
+		return
+	}
+	if t.err == nil {
+		t.err = err
+	}
+	t.tags = append(t.tags, err.Error())
+	t.unlock()
+	t.Stop()
+}
@@ -0,0 +1,68 @@
+package timers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStopCanceled(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock)
+	done := s.New("done").Start()
+	notStarted := s.New("not started")
+	ctx, request := NewContextWithTimer(ctx, "request")
+	request.Start()
+	child := From(ctx).New("child").Start()
+	clock.Advance(time.Millisecond)
+	done.Stop()
+	clock.Advance(time.Millisecond)
+
+	s.stopCanceled(context.DeadlineExceeded)
+	clock.Advance(time.Millisecond)
+	s.StopAllTimers()
+	for _, timer := range []*Timer{request, child} {
+		if timer.IsRunning() || timer.Duration() != 2*time.Millisecond {
+			t.Errorf("%s wasn't stopped when cancelled", timer.copy())
+		}
+		if !errors.Is(timer.Err(), context.DeadlineExceeded) || !sameNames(timer.Tags(), "context deadline exceeded") {
+			t.Errorf("%s wasn't failed with the context's error", timer.copy())
+		}
+	}
+	if done.Failed() || len(done.Tags()) != 0 || notStarted.Failed() || notStarted.IsRunning() {
+		t.Error("Timers that weren't running were changed")
+	}
+	if leaks := s.Leaks(); len(leaks) != 1 || leaks[0].Path != "not started" {
+		t.Errorf("Cancelled timers were reported as leaks: %v", leaks)
+	}
+}
+
+func TestStopCanceledKeepsError(t *testing.T) {
+	s := newSet()
+	timer := s.New("failed").Start().Fail(errBoom)
+	s.stopCanceled(context.Canceled)
+	if timer.Err() != errBoom || !sameNames(timer.Tags(), "context canceled") {
+		t.Errorf("Cancelling replaced the timer's error: %s", timer.copy())
+	}
+}
+
+func TestStopOnDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = NewContext(ctx)
+	timer := From(ctx).StopOnDone(ctx).New("request").Start()
+	cancel()
+	for deadline := time.Now().Add(5 * time.Second); timer.IsRunning(); {
+		if time.Now().After(deadline) {
+			t.Fatal("Timer wasn't stopped when the context was cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !errors.Is(timer.Err(), context.Canceled) {
+		t.Errorf("Timer's error was %v", timer.Err())
+	}
+
+	// A context that can't be cancelled doesn't start a go routine
+	From(context.Background()).StopOnDone(context.Background())
+}
@@ -0,0 +1,95 @@
+package timers
+
+import (
+	"sync"
+	"time"
+)
+
+// A Clock provides the current time to timers. By default timers use the system clock, but a
+// TimerSet can be given a different Clock with SetClock, which is mostly useful for testing
+// with a ManualClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// The system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// A ManualClock is a Clock that only changes when told to. Use it in tests to get exact
+// durations:
+//  clock := timers.NewManualClock(time.Unix(0, 0))
+//  timers.From(ctx).SetClock(clock)
+//  t := timers.From(ctx).New("test").Start()
+//  clock.Advance(5 * time.Millisecond)
+//  t.Stop() // t.Duration() is exactly 5ms
+//
+// A ManualClock is safe for concurrent use.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Returns a new ManualClock set to the provided time. Timers treat a zero start time as not
+// started, so if now is the zero time the clock starts at the Unix epoch instead.
+func NewManualClock(now time.Time) *ManualClock {
+	if now.IsZero() {
+		now = time.Unix(0, 0)
+	}
+	return &ManualClock{now: now}
+}
+
+// Returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Sets the clock to the provided time.
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Sets the Clock used by timers created in this TimerSet after this call, and by TimerSets
+// created from it with NewContext, NewContextWithTimer and Wrap. A nil clock sets it back to
+// the system clock.
+func (s *TimerSet) SetClock(clock Clock) *TimerSet {
+	if s.readOnly() {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+	return s
+}
+
+// Returns the Clock used by this TimerSet.
+func (s *TimerSet) Clock() Clock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clock == nil {
+		return realClock{}
+	}
+	return s.clock
+}
+
+// Returns the current time from the timer's clock.
+func (t *Timer) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
@@ -0,0 +1,111 @@
+package timers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	epoch := time.Unix(1644884400, 0)
+	clock := NewManualClock(epoch)
+	if !clock.Now().Equal(epoch) {
+		t.Error("ManualClock didn't start at the provided time")
+	}
+	clock.Advance(time.Second)
+	if !clock.Now().Equal(epoch.Add(time.Second)) {
+		t.Error("ManualClock didn't advance")
+	}
+	clock.Set(epoch)
+	if !clock.Now().Equal(epoch) {
+		t.Error("ManualClock wasn't set")
+	}
+	if NewManualClock(time.Time{}).Now().IsZero() {
+		t.Error("ManualClock started at the zero time")
+	}
+}
+
+func TestClockDurations(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	s := newSet().SetClock(clock)
+	if s.Clock() != clock {
+		t.Error("Clock() didn't return the clock that was set")
+	}
+	timer := s.New("exact").Start()
+	clock.Advance(3 * time.Millisecond)
+	if timer.Duration() != 3*time.Millisecond {
+		t.Errorf("Running timer's duration was %s not 3ms", timer.Duration())
+	}
+	timer.Lap("lap").Pause()
+	clock.Advance(10 * time.Millisecond)
+	timer.Resume()
+	clock.Advance(2 * time.Millisecond)
+	timer.Stop()
+	clock.Advance(time.Hour)
+	if timer.Duration() != 15*time.Millisecond {
+		t.Errorf("Timer's duration was %s not 15ms", timer.Duration())
+	}
+	if timer.ActiveDuration() != 5*time.Millisecond {
+		t.Errorf("Timer's active duration was %s not 5ms", timer.ActiveDuration())
+	}
+	if timer.Laps()[0].Split != 3*time.Millisecond {
+		t.Errorf("Lap was %s not 3ms", timer.Laps()[0].Split)
+	}
+
+	zero := s.New("zero").Start().Stop()
+	if zero.IsRunning() || zero.Duration() != 0 {
+		t.Error("Timer stopped at the same instant it started isn't stopped with a zero duration")
+	}
+
+	if _, ok := newSet().Clock().(realClock); !ok {
+		t.Error("TimerSet without a clock doesn't use the system clock")
+	}
+	if _, ok := s.SetClock(nil).Clock().(realClock); !ok {
+		t.Error("Setting a nil clock doesn't use the system clock")
+	}
+}
+
+func TestClockInherited(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	From(ctx).SetClock(clock)
+
+	ctx1 := NewContext(ctx)
+	ctx2, t2 := NewContextWithTimer(ctx1, "with timer")
+	if From(ctx1).Clock() != clock || From(ctx2).Clock() != clock {
+		t.Error("Child TimerSet didn't inherit the clock")
+	}
+	t2.Start()
+	From(ctx2).Wrap(ctx2, "wrapped", func(ctx context.Context) {
+		if From(ctx).Clock() != clock {
+			t.Error("Wrapped TimerSet didn't inherit the clock")
+		}
+		clock.Advance(7 * time.Millisecond)
+	})
+	t2.Stop()
+	if t2.Duration() != 7*time.Millisecond {
+		t.Errorf("Timer's duration was %s not 7ms", t2.Duration())
+	}
+	if d := From(ctx2).Find("wrapped").Duration(); d != 7*time.Millisecond {
+		t.Errorf("Wrapped timer's duration was %s not 7ms", d)
+	}
+}
+
+func TestClockHeader(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	s.New("Not started")
+	timer := s.New("Test").Start()
+	clock.Advance(5500 * time.Microsecond)
+	timer.Stop()
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	expect := `Not_started;descr="Not started";dur=0;parent=0;id=1, ` +
+		`Test;descr="Test";dur=5.500;start=1644884400000;parent=0;id=2`
+	if header != expect {
+		t.Errorf("Header was\n%s\nexpected\n%s", header, expect)
+	}
+}
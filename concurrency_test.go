@@ -0,0 +1,198 @@
+// Concurrency stress tests. These are only really useful when run with the race detector:
+//  go test -race
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+const stressWorkers = 16
+const stressLoops = 200
+
+// Runs fn in stressWorkers go routines at the same time, and waits for them all to finish.
+func stress(fn func(worker int)) {
+	wg := sync.WaitGroup{}
+	start := make(chan struct{})
+	for i := 0; i < stressWorkers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+}
+
+// Exercises every read path of a TimerSet.
+func readEverything(t *testing.T, s *TimerSet) {
+	s.All()
+	s.AllDeep()
+	s.Snapshot()
+	_ = s.String()
+	s.Tree(func(timer Timer, _ int, _ *TimerSet) {
+		_ = timer.String()
+		timer.Children()
+	})
+	if _, err := json.Marshal(s); err != nil {
+		t.Error(err)
+	}
+	s.AddHeader(httptest.NewRecorder())
+}
+
+func TestConcurrentSameTimer(t *testing.T) {
+	s := newSet()
+	timer := s.New("shared")
+	stress(func(worker int) {
+		for i := 0; i < stressLoops; i++ {
+			switch worker % 4 {
+			case 0:
+				timer.Start()
+				timer.Tag("started")
+			case 1:
+				timer.Stop()
+				timer.Duration()
+				timer.Milliseconds()
+			case 2:
+				timer.IsRunning()
+				timer.Tags()
+				timer.Compare(timer)
+			case 3:
+				readEverything(t, s)
+			}
+		}
+	})
+	if timer.IsRunning() {
+		t.Error("Timer was still running after every worker had stopped it")
+	}
+}
+
+func TestConcurrentMarshalTimer(t *testing.T) {
+	s := newSet()
+	stress(func(worker int) {
+		for i := 0; i < stressLoops/20; i++ {
+			timer := s.New("marshal %d.%d", worker, i).Start()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				timer.Lap("lap").Attr("i", i)
+				timer.Stop()
+			}()
+			if _, err := json.Marshal(timer); err != nil {
+				t.Error(err)
+			}
+			<-done
+		}
+	})
+}
+
+func TestConcurrentLaps(t *testing.T) {
+	timer := newSet().New("laps").Start()
+	stress(func(worker int) {
+		for i := 0; i < stressLoops; i++ {
+			timer.Lap("lap")
+		}
+	})
+	for _, lap := range timer.Laps() {
+		if lap.Duration < 0 {
+			t.Fatalf("Laps were recorded out of order: %v", timer.Laps())
+		}
+	}
+}
+
+func TestConcurrentEvents(t *testing.T) {
+	timer := newSet().New("events").Start()
+	stress(func(worker int) {
+		for i := 0; i < stressLoops; i++ {
+			timer.Event("event")
+		}
+	})
+	events := timer.Events()
+	for i := 1; i < len(events); i++ {
+		if events[i].Time.Before(events[i-1].Time) {
+			t.Fatalf("Events were recorded out of order at %d", i)
+		}
+	}
+}
+
+func TestConcurrentStopAllTimers(t *testing.T) {
+	ctx := NewContext(context.Background())
+	stress(func(worker int) {
+		for i := 0; i < stressLoops; i++ {
+			if worker%2 == 0 {
+				timer := From(ctx).New("worker %d.%d", worker, i).Start()
+				timer.Tag("tag")
+				timer.Stop()
+			} else if i%100 == 0 {
+				From(ctx).StopAllTimers()
+				readEverything(t, From(ctx))
+			}
+		}
+	})
+	for _, timer := range From(ctx).AllDeep() {
+		if timer.IsRunning() {
+			t.Errorf("Timer %s was left running", timer.name)
+		}
+	}
+}
+
+func TestConcurrentTree(t *testing.T) {
+	ctx := NewContext(context.Background())
+	stress(func(worker int) {
+		for i := 0; i < stressLoops/20; i++ {
+			switch worker % 3 {
+			case 0:
+				From(ctx).Wrap(ctx, "wrap", func(ctx context.Context) {
+					From(ctx).New("inside").Start().Stop()
+				})
+			case 1:
+				newCtx, timer := NewContextWithTimer(ctx, "worker %d", worker)
+				timer.Start()
+				From(newCtx).New("child").Start().Stop()
+				From(NewContext(newCtx)).New("grandchild").Start()
+				timer.Stop()
+			case 2:
+				From(ctx).StopAllTimers()
+				readEverything(t, From(ctx))
+			}
+		}
+	})
+	From(ctx).StopAllTimers()
+	for _, timer := range From(ctx).AllDeep() {
+		if timer.IsRunning() {
+			t.Errorf("Timer %s was left running", timer.name)
+		}
+	}
+}
+
+func TestConcurrentBackgroundWork(t *testing.T) {
+	// The middleware stops all timers and writes the header while the handler may still have
+	// go routines working with their own timers.
+	ctx := NewContext(context.Background())
+	request := From(ctx).New("Request").Start()
+	done := make(chan struct{})
+	for i := 0; i < stressWorkers; i++ {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			From(ctx).Wrap(ctx, "background", func(ctx context.Context) {
+				for j := 0; j < stressLoops; j++ {
+					From(ctx).New("work %d", j).Start().Tag("bg").Stop()
+				}
+			})
+		}(i)
+	}
+	request.Stop()
+	From(ctx).StopAllTimers()
+	From(ctx).AddHeader(httptest.NewRecorder())
+	for i := 0; i < stressWorkers; i++ {
+		<-done
+	}
+	if len(From(ctx).AllDeep()) != 1+stressWorkers*(1+stressLoops) {
+		t.Errorf("Expected %d timers, got %d", 1+stressWorkers*(1+stressLoops), len(From(ctx).AllDeep()))
+	}
+}
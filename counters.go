@@ -0,0 +1,159 @@
+package timers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Counter is a named number accumulated on a timer with Add(), such as the rows or bytes a
+// call returned.
+type Counter struct {
+	Name  string
+	Value int64
+}
+
+// Adds n to the named counter on the timer, creating it if needed. Counters can be added to at
+// any time, whether or not the timer is running.
+//  t := timers.From(ctx).New("db query").Start()
+//  rows := query()
+//  t.Add("rows", int64(len(rows))).Stop()
+func (t *Timer) Add(counter string, n int64) *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
+	for i := range t.counters {
+		if t.counters[i].Name == counter {
+			t.counters[i].Value += n
+			return t
+		}
+	}
+	t.counters = append(t.counters, Counter{Name: counter, Value: n})
+	return t
+}
+
+// Returns the value of the named counter, or zero if it hasn't been added to.
+func (t *Timer) Counter(name string) int64 {
+	t.lock()
+	defer t.unlock()
+	for _, c := range t.counters {
+		if c.Name == name {
+			return c.Value
+		}
+	}
+	return 0
+}
+
+// Returns a copy of the counters on the timer, in the order they were first added to.
+func (t *Timer) Counters() []Counter {
+	t.lock()
+	defer t.unlock()
+	counters := make([]Counter, len(t.counters))
+	copy(counters, t.counters)
+	return counters
+}
+
+// Returns the roll-up of the counters on the timer and every timer under it (see
+// NewContextWithTimer and Wrap), summed by name.
+func (t *Timer) Totals() []Counter {
+	c := t.copy()
+	return c.totals()
+}
+
+// Caller must hold the lock, or be using a copy.
+func (t *Timer) totals() []Counter {
+	totals := sumCounters(nil, t.counters)
+	if t.subtimer != nil {
+		totals = t.subtimer.sumCounters(totals)
+	}
+	return totals
+}
+
+// Returns the roll-up of the counters on every timer in the tree, summed by name.
+func (s *TimerSet) Totals() []Counter {
+	return s.sumCounters(nil)
+}
+
+func (s *TimerSet) sumCounters(totals []Counter) []Counter {
+	for _, t := range s.AllDeep() {
+		totals = sumCounters(totals, t.counters)
+	}
+	return totals
+}
+
+// Adds the counters to the totals, in the order they are first seen.
+func sumCounters(totals []Counter, counters []Counter) []Counter {
+next:
+	for _, c := range counters {
+		for i := range totals {
+			if totals[i].Name == c.Name {
+				totals[i].Value += c.Value
+				continue next
+			}
+		}
+		totals = append(totals, c)
+	}
+	return totals
+}
+
+// Returns true if the timer has children with counters, so the totals differ from the counters.
+// Caller must hold the lock, or be using a copy.
+func (t *Timer) hasChildCounters() bool {
+	if t.subtimer == nil {
+		return false
+	}
+	for _, c := range t.subtimer.AllDeep() {
+		if len(c.counters) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Formats a list of counters for Timer.String()
+func fmtCounters(counters []Counter) string {
+	str := make([]string, len(counters))
+	for i, c := range counters {
+		str[i] = fmt.Sprintf("%s=%d", c.Name, c.Value)
+	}
+	return strings.Join(str, ",")
+}
+
+// Formats a list of counters as Server-Timing parameters, each counter name prefixed with
+// prefix and made safe to use as a parameter name, eg: ;count.rows=12000;count.bytes=3000000
+func fmtCountersHeader(prefix string, counters []Counter) string {
+	var str string
+	for _, c := range counters {
+		str += fmt.Sprintf(";%s.%s=%d", prefix, headerNamePattern.ReplaceAllString(c.Name, "_"), c.Value)
+	}
+	return str
+}
+
+// Marshaling type
+type marshalCounter struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+func toMarshalCounters(counters []Counter) *[]marshalCounter {
+	if len(counters) == 0 {
+		return nil
+	}
+	list := make([]marshalCounter, len(counters))
+	for i, c := range counters {
+		list[i] = marshalCounter(c)
+	}
+	return &list
+}
+
+func fromMarshalCounters(list *[]marshalCounter) []Counter {
+	if list == nil {
+		return nil
+	}
+	counters := make([]Counter, len(*list))
+	for i, mc := range *list {
+		counters[i] = Counter(mc)
+	}
+	return counters
+}
@@ -0,0 +1,95 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCounters(t *testing.T) {
+	timer := newSet().New("db query")
+	timer.Add("rows", 10).Add("bytes", 2048).Start().Add("rows", 5).Stop().Add("rows", -1)
+
+	expect := []Counter{{"rows", 14}, {"bytes", 2048}}
+	if counters := timer.Counters(); !reflect.DeepEqual(counters, expect) {
+		t.Errorf("Counters were %v, not %v", counters, expect)
+	}
+	if timer.Counter("rows") != 14 || timer.Counter("missing") != 0 {
+		t.Error("Counter() returned the wrong value")
+	}
+	timer.Counters()[0].Value = 0
+	if timer.Counter("rows") != 14 {
+		t.Error("Counters() didn't return a copy")
+	}
+	if str := timer.copy().String(); !strings.HasSuffix(str, " counters:(rows=14,bytes=2048)") {
+		t.Errorf("String() doesn't include the counters: %s", str)
+	}
+}
+
+// Returns a set with a "request" timer, which has rows=1, and children with more rows and bytes.
+func counterTree() *TimerSet {
+	ctx := NewContext(context.Background())
+	s := From(ctx)
+	ctx, request := NewContextWithTimer(ctx, "request")
+	request.Add("rows", 1)
+	From(ctx).New("db").Add("rows", 10).Add("bytes", 100)
+	From(ctx).Wrap(ctx, "batch", func(ctx context.Context) {
+		From(ctx).New("db").Add("rows", 20)
+	})
+	s.New("other").Add("calls", 1)
+	return s
+}
+
+func TestCounterTotals(t *testing.T) {
+	s := counterTree()
+	expect := []Counter{{"rows", 31}, {"bytes", 100}}
+	if totals := s.Find("request").Totals(); !reflect.DeepEqual(totals, expect) {
+		t.Errorf("Totals were %v, not %v", totals, expect)
+	}
+	expect = []Counter{{"rows", 31}, {"calls", 1}, {"bytes", 100}}
+	if totals := s.Totals(); !reflect.DeepEqual(totals, expect) {
+		t.Errorf("TimerSet totals were %v, not %v", totals, expect)
+	}
+	if totals := s.Find("other").Totals(); !reflect.DeepEqual(totals, []Counter{{"calls", 1}}) {
+		t.Errorf("Totals of a timer without children were %v", totals)
+	}
+	str := s.String()
+	if !strings.Contains(str, "request: NotStarted counters:(rows=1) totals:(rows=31,bytes=100)") {
+		t.Errorf("String() doesn't include the totals:\n%s", str)
+	}
+	if strings.Contains(str, "other: NotStarted counters:(calls=1) totals") {
+		t.Errorf("String() includes totals for a timer without children:\n%s", str)
+	}
+}
+
+func TestCountersExported(t *testing.T) {
+	s := counterTree()
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"counters":[{"name":"rows","value":1}],"totals":[{"name":"rows","value":31},{"name":"bytes","value":100}]`) {
+		t.Errorf("JSON doesn't include the counters and totals: %s", b)
+	}
+	var imported TimerSet
+	if err := json.Unmarshal(b, &imported); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(imported.Totals(), s.Totals()) {
+		t.Errorf("Imported totals were %v, not %v", imported.Totals(), s.Totals())
+	}
+
+	s.Find("other").Add("bad name!", 2)
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.Contains(header, ";count.rows=1;total.rows=31;total.bytes=100,") {
+		t.Errorf("Header doesn't include the counters and totals: %s", header)
+	}
+	if !strings.Contains(header, ";count.calls=1;count.bad_name_=2") {
+		t.Errorf("Header doesn't include safe counter names: %s", header)
+	}
+}
@@ -0,0 +1,94 @@
+package timers
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// A Path is a chain of timers, each one in the child TimerSet of the one before it.
+type Path []*Timer
+
+// Returns the chain of timers that determined the end-to-end latency: the timer in this TimerSet
+// that finished last, then the timer in its child TimerSet (see NewContextWithTimer and Wrap)
+// that finished last, and so on down the tree. When work runs in parallel, this is the branch
+// that everything else was waiting on. Running timers are treated as finishing now, and marks
+// and timers that haven't started are ignored. The timers NewContext creates to hold a child
+// TimerSet are never started, so they are treated as running from the start of the first timer
+// in it to the end of the last, and the path goes through them without including them. Like
+// AllDeep(), these are copies, not the original timers.
+//  fmt.Println(timers.From(ctx).CriticalPath())
+func (s *TimerSet) CriticalPath() Path {
+	var path Path
+	s = s.Snapshot()
+	for s != nil {
+		t := s.lastToFinish()
+		if t == nil {
+			break
+		}
+		if !t.group {
+			path = append(path, t)
+		}
+		s = t.subtimer
+	}
+	return path
+}
+
+// Returns a copy of the timer in the set that finished last, or nil if none have started.
+func (s *TimerSet) lastToFinish() *Timer {
+	var last *Timer
+	var lastEnd time.Time
+	timers := s.All()
+	for i := range timers {
+		t := &timers[i]
+		_, end, ok := t.span()
+		if !ok {
+			continue
+		}
+		if last == nil || end.After(lastEnd) {
+			last, lastEnd = t, end
+		}
+	}
+	return last
+}
+
+// Returns when the timer started and finished, or finishes now if it is running. The timers
+// NewContext creates are never started, so they span the timers in their child TimerSet. ok is
+// false for marks, and timers that haven't started. Caller must hold the lock, or be using a copy.
+func (t *Timer) span() (start, end time.Time, ok bool) {
+	if t.group && t.subtimer != nil {
+		for _, i := range t.subtimer.intervals() {
+			if !ok || i.start.Before(start) {
+				start = i.start
+			}
+			if !ok || i.end.After(end) {
+				end = i.end
+			}
+			ok = true
+		}
+		return start, end, ok
+	}
+	if t.start.IsZero() || t.mark {
+		return start, end, false
+	}
+	return t.start, t.start.Add(t.wallDuration()), true
+}
+
+// Renders the path as a text table, with each timer indented under the one before it. Start is
+// the offset from the start of the first timer in the path, and Self is the time the timer spent
+// outside its children (see SelfDuration).
+func (p Path) String() string {
+	if len(p) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tStart\tDuration\tSelf")
+	for i, t := range p {
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\n", strings.Repeat("  ", i), t.name,
+			fmtMs(t.start.Sub(p[0].start)), fmtMs(t.wallDuration()), fmtMs(t.selfDuration()))
+	}
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,90 @@
+package timers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCriticalPath(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock)
+	s.New("not started")
+	setup := s.New("setup").Start()
+	clock.Advance(5 * time.Millisecond)
+	setup.Stop()
+
+	ctx, request := NewContextWithTimer(ctx, "request")
+	request.Start()
+	// Two branches running in parallel, the second one finishes last
+	fast := From(ctx).New("fast").Start()
+	slowCtx, slow := NewContextWithTimer(ctx, "slow")
+	slow.Start()
+	clock.Advance(10 * time.Millisecond)
+	fast.Stop()
+	From(slowCtx).New("query").Start()
+	clock.Advance(20 * time.Millisecond)
+	From(slowCtx).Find("query").Stop()
+	slow.Stop()
+	clock.Advance(5 * time.Millisecond)
+	request.Stop()
+
+	path := s.CriticalPath()
+	if !sameNames(names(path), "request", "slow", "query") {
+		t.Errorf("Critical path was %v", names(path))
+	}
+	expect := "Name       Start     Duration  Self\n" +
+		"request    0.000ms   35.000ms  5.000ms\n" +
+		"  slow     0.000ms   30.000ms  10.000ms\n" +
+		"    query  10.000ms  20.000ms  20.000ms"
+	if path.String() != expect {
+		t.Errorf("Critical path was\n%s\nexpected\n%s", path.String(), expect)
+	}
+}
+
+func TestCriticalPathNewContext(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock)
+	s.New("setup").Start()
+	clock.Advance(5 * time.Millisecond)
+	s.Find("setup").Stop()
+	// Each handler makes its own context, so its timers are under a "Subtimer" that never starts
+	handler := func(ctx context.Context, name string, d time.Duration) {
+		ctx, timer := NewContextWithTimer(NewContext(ctx), name)
+		timer.Start()
+		query := From(NewContext(ctx)).New(name + " query").Start()
+		clock.Advance(d)
+		query.Stop()
+		timer.Stop()
+	}
+	handler(ctx, "short", 2*time.Millisecond)
+	handler(ctx, "long", 10*time.Millisecond)
+	s.New("earlier").Start().Stop()
+
+	if path := s.CriticalPath(); !sameNames(names(path), "long", "long query") {
+		t.Errorf("Critical path was %v", names(path))
+	}
+}
+
+func TestCriticalPathRunning(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	s := newSet().SetClock(clock)
+	s.New("stopped").Start()
+	clock.Advance(time.Millisecond)
+	s.Find("stopped").Stop()
+	s.New("running").Start()
+	clock.Advance(time.Millisecond)
+	if path := s.CriticalPath(); !sameNames(names(path), "running") {
+		t.Errorf("Critical path was %v, not the running timer", names(path))
+	}
+}
+
+func TestCriticalPathEmpty(t *testing.T) {
+	s := newSet()
+	s.New("not started")
+	if path := s.CriticalPath(); len(path) != 0 || path.String() != "" {
+		t.Errorf("Critical path of timers that haven't started was %v", names(path))
+	}
+}
@@ -0,0 +1,171 @@
+package timers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// DiffStatus is how a timer differs between the two TimerSets passed to Diff.
+type DiffStatus string
+
+const (
+	DiffMatched DiffStatus = "matched" // The timer is in both
+	DiffAdded   DiffStatus = "added"   // The timer is only in the second
+	DiffRemoved DiffStatus = "removed" // The timer is only in the first
+)
+
+// A Change is the difference in a single timer between two TimerSets.
+type Change struct {
+	Path    string // Names of the timer's parents and the timer, separated by '/'
+	Name    string
+	Status  DiffStatus
+	Before  time.Duration // Zero if the timer was added
+	After   time.Duration // Zero if the timer was removed
+	Delta   time.Duration // After less Before
+	Percent float64       // Delta as a percentage of Before, zero unless the timer matched
+
+	key   string // Name, with #n appended for duplicates
+	depth int
+}
+
+// Changes are the result of Diff, in the order of the tree (each timer followed by its
+// children).
+type Changes []Change
+
+// Compares two TimerSets, such as before and after captures of the same request, and returns
+// every timer in either of them with its change in duration. Timers are matched by their path
+// (the names of the timer and its parents, as in FindPath). If there is more than one timer with
+// the same name in a TimerSet, they are matched in order, and the path of the second onwards
+// has "#n" appended to the name, eg "Request/db#2". Timers that haven't started have a duration
+// of zero. Either TimerSet may be nil.
+//  fmt.Println(timers.Diff(before, after))
+func Diff(a, b *TimerSet) Changes {
+	var changes Changes
+	changes.diffSets(a, b, "", 0)
+	return changes
+}
+
+type diffTimer struct {
+	key   string
+	timer *Timer
+}
+
+// Returns copies of the timers in the set with their names made unique, or nil if the set is nil.
+func diffTimers(s *TimerSet) []diffTimer {
+	if s == nil {
+		return nil
+	}
+	all := s.All()
+	timers := make([]diffTimer, len(all))
+	seen := make(map[string]int)
+	for i := range all {
+		t := &all[i]
+		seen[t.name]++
+		key := t.name
+		if n := seen[t.name]; n > 1 {
+			key = fmt.Sprintf("%s#%d", t.name, n)
+		}
+		timers[i] = diffTimer{key: key, timer: t}
+	}
+	return timers
+}
+
+func (changes *Changes) diffSets(a, b *TimerSet, prefix string, depth int) {
+	before, after := diffTimers(a), diffTimers(b)
+	afterByKey := make(map[string]*Timer, len(after))
+	for _, dt := range after {
+		afterByKey[dt.key] = dt.timer
+	}
+	matched := make(map[string]bool)
+	for _, da := range before {
+		c := Change{Path: prefix + da.key, Name: da.timer.name, Before: da.timer.wallDuration(), key: da.key, depth: depth}
+		tb := afterByKey[da.key]
+		if tb == nil {
+			c.Status = DiffRemoved
+			c.Delta = -c.Before
+			*changes = append(*changes, c)
+			changes.diffSets(da.timer.subtimer, nil, c.Path+"/", depth+1)
+			continue
+		}
+		matched[da.key] = true
+		c.Status = DiffMatched
+		c.After = tb.wallDuration()
+		c.Delta = c.After - c.Before
+		if c.Before != 0 {
+			c.Percent = float64(c.Delta) / float64(c.Before) * 100
+		}
+		*changes = append(*changes, c)
+		changes.diffSets(da.timer.subtimer, tb.subtimer, c.Path+"/", depth+1)
+	}
+	for _, db := range after {
+		if matched[db.key] {
+			continue
+		}
+		c := Change{Path: prefix + db.key, Name: db.timer.name, Status: DiffAdded, After: db.timer.wallDuration(),
+			Delta: db.timer.wallDuration(), key: db.key, depth: depth}
+		*changes = append(*changes, c)
+		changes.diffSets(nil, db.timer.subtimer, c.Path+"/", depth+1)
+	}
+}
+
+// Renders the changes as a text table, with durations in milliseconds. Each timer is indented
+// under its parent (with "#n" appended to duplicate names), and added and removed timers are
+// marked with a '+' or '-'.
+func (changes Changes) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Name\tBefore\tAfter\tDelta\tChange")
+	for _, c := range changes {
+		mark, before, after, percent := " ", fmtMs(c.Before), fmtMs(c.After), ""
+		switch c.Status {
+		case DiffAdded:
+			mark, before = "+", "-"
+		case DiffRemoved:
+			mark, after = "-", "-"
+		default:
+			if c.Before != 0 {
+				percent = fmt.Sprintf("%+.1f%%", c.Percent)
+			}
+		}
+		fmt.Fprintf(w, "%s %s%s\t%s\t%s\t%+.3fms\t%s\n", mark, strings.Repeat("  ", c.depth), c.key,
+			before, after, float64(c.Delta)/float64(time.Millisecond), percent)
+	}
+	w.Flush()
+	lines := strings.Split(strings.TrimRight(b.String(), "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Durations are exported in milliseconds, as with timers. Percent is only included for matched
+// timers that had a duration before.
+type marshalChange struct {
+	Path    string     `json:"path"`
+	Name    string     `json:"name"`
+	Status  DiffStatus `json:"status"`
+	Before  float64    `json:"before"`
+	After   float64    `json:"after"`
+	Delta   float64    `json:"delta"`
+	Percent *float64   `json:"percent,omitempty"`
+}
+
+// Marshals the change to JSON.
+func (c Change) MarshalJSON() ([]byte, error) {
+	mc := marshalChange{
+		Path:   c.Path,
+		Name:   c.Name,
+		Status: c.Status,
+		Before: durationMs(c.Before),
+		After:  durationMs(c.After),
+		Delta:  durationMs(c.Delta),
+	}
+	if c.Status == DiffMatched && c.Before != 0 {
+		percent := c.Percent
+		mc.Percent = &percent
+	}
+	return json.Marshal(mc)
+}
@@ -0,0 +1,103 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// Builds a request tree where each timer runs for the provided number of milliseconds. A
+// duration of zero leaves the timer out.
+func diffTree(request, db1, db2, cache, removed, added int) *TimerSet {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock)
+	timed := func(s *TimerSet, name string, ms int) {
+		if ms != 0 {
+			t := s.New(name).Start()
+			clock.Advance(time.Duration(ms) * time.Millisecond)
+			t.Stop()
+		}
+	}
+	ctx, r := NewContextWithTimer(ctx, "request")
+	r.Start()
+	timed(From(ctx), "db", db1)
+	timed(From(ctx), "db", db2)
+	timed(From(ctx), "cache", cache)
+	timed(From(ctx), "removed", removed)
+	timed(From(ctx), "added", added)
+	clock.Set(time.Unix(0, 0).Add(time.Duration(request) * time.Millisecond))
+	r.Stop()
+	return s
+}
+
+func TestDiff(t *testing.T) {
+	before := diffTree(100, 10, 20, 5, 3, 0)
+	after := diffTree(150, 10, 40, 0, 0, 7)
+	changes := Diff(before, after)
+
+	expect := []Change{
+		{Path: "request", Name: "request", Status: DiffMatched, Before: 100, After: 150, Delta: 50, Percent: 50},
+		{Path: "request/db", Name: "db", Status: DiffMatched, Before: 10, After: 10},
+		{Path: "request/db#2", Name: "db", Status: DiffMatched, Before: 20, After: 40, Delta: 20, Percent: 100},
+		{Path: "request/cache", Name: "cache", Status: DiffRemoved, Before: 5, Delta: -5},
+		{Path: "request/removed", Name: "removed", Status: DiffRemoved, Before: 3, Delta: -3},
+		{Path: "request/added", Name: "added", Status: DiffAdded, After: 7, Delta: 7},
+	}
+	if len(changes) != len(expect) {
+		t.Fatalf("Diff returned %d changes, not %d: %v", len(changes), len(expect), changes)
+	}
+	for i, e := range expect {
+		c := changes[i]
+		e.Before *= time.Millisecond
+		e.After *= time.Millisecond
+		e.Delta *= time.Millisecond
+		if c.Path != e.Path || c.Name != e.Name || c.Status != e.Status || c.Before != e.Before ||
+			c.After != e.After || c.Delta != e.Delta || c.Percent != e.Percent {
+			t.Errorf("Change %d was %+v, expected %+v", i, c, e)
+		}
+	}
+
+	expectStr := "  Name       Before     After      Delta      Change\n" +
+		"  request    100.000ms  150.000ms  +50.000ms  +50.0%\n" +
+		"    db       10.000ms   10.000ms   +0.000ms   +0.0%\n" +
+		"    db#2     20.000ms   40.000ms   +20.000ms  +100.0%\n" +
+		"-   cache    5.000ms    -          -5.000ms\n" +
+		"-   removed  3.000ms    -          -3.000ms\n" +
+		"+   added    -          7.000ms    +7.000ms"
+	if changes.String() != expectStr {
+		t.Errorf("Diff was\n%s\nexpected\n%s", changes.String(), expectStr)
+	}
+}
+
+func TestDiffSubtrees(t *testing.T) {
+	a := diffTree(100, 10, 0, 0, 0, 0)
+	changes := Diff(a, nil)
+	if len(changes) != 2 || changes[0].Status != DiffRemoved || changes[1].Status != DiffRemoved ||
+		changes[1].Path != "request/db" {
+		t.Errorf("Diff against nil didn't remove the whole tree: %v", changes)
+	}
+	changes = Diff(nil, a)
+	if len(changes) != 2 || changes[0].Status != DiffAdded || changes[1].Status != DiffAdded {
+		t.Errorf("Diff from nil didn't add the whole tree: %v", changes)
+	}
+	if changes := Diff(nil, nil); len(changes) != 0 {
+		t.Errorf("Diff of nil sets returned changes: %v", changes)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	before := diffTree(100, 10, 0, 5, 0, 0)
+	after := diffTree(150, 10, 0, 0, 0, 0)
+	b, err := json.Marshal(Diff(before, after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `[{"path":"request","name":"request","status":"matched","before":100,"after":150,"delta":50,"percent":50},` +
+		`{"path":"request/db","name":"db","status":"matched","before":10,"after":10,"delta":0,"percent":0},` +
+		`{"path":"request/cache","name":"cache","status":"removed","before":5,"after":0,"delta":-5}]`
+	if string(b) != expect {
+		t.Errorf("JSON was\n%s\nexpected\n%s", b, expect)
+	}
+}
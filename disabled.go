@@ -0,0 +1,45 @@
+package timers
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Non zero when timers are disabled globally, see SetEnabled
+var disabled int32
+
+// The TimerSet and Timer handed out while timers are disabled. They are shared by everyone, so
+// they never change: every method that would modify them does nothing instead.
+var noopSet = &TimerSet{disabled: true}
+var noopTimer = &Timer{disabled: true}
+
+// Enables or disables all timers. While disabled, From() returns a TimerSet that does nothing,
+// so From, New, Start, Stop, Tag and Measure do not allocate, and instrumentation can be left in
+// hot paths at (almost) no cost. Note that the arguments to a formatted New() may still be
+// allocated by the caller.
+//
+// Timers are enabled by default. To disable timers for just one context, see NewDisabledContext.
+func SetEnabled(enabled bool) {
+	if enabled {
+		atomic.StoreInt32(&disabled, 0)
+	} else {
+		atomic.StoreInt32(&disabled, 1)
+	}
+}
+
+// Returns true unless timers have been disabled with SetEnabled(false)
+func Enabled() bool {
+	return atomic.LoadInt32(&disabled) == 0
+}
+
+// Returns a context in which timers are disabled. From() on this context, and any context
+// derived from it, returns a TimerSet that does nothing. See SetEnabled.
+func NewDisabledContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timerctx("timers"), noopSet)
+}
+
+// Returns false if this TimerSet is disabled, and will not record any timers. This is useful to
+// skip expensive work that is only needed for timers, like formatting attributes.
+func (s *TimerSet) Enabled() bool {
+	return !s.disabled
+}
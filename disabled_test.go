@@ -0,0 +1,143 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// The instrumentation that should not allocate when timers are disabled.
+func instrumented(ctx context.Context) {
+	defer From(ctx).New("function").Start().Stop()
+	t := From(ctx).New("with tag").Tag("tag").Budget(time.Second).Start()
+	t.Lap("lap").Event("event").Add("rows", 1).Pause().Resume()
+	t.Stop()
+	From(ctx).Mark("mark")
+	From(ctx).New("measured").Measure(func() {})
+	From(ctx).New("measured error").MeasureErr(func() error { return nil })
+	Do(ctx, "do", func(ctx context.Context) (int, error) { return 1, nil })
+	DoErr(ctx, "do error", func(ctx context.Context) error { return nil })
+}
+
+func TestDisabledAllocations(t *testing.T) {
+	SetEnabled(false)
+	defer SetEnabled(true)
+	ctx := context.Background()
+	run := func() {
+		instrumented(ctx)
+		New("global").Start().Stop()
+	}
+	if allocs := testing.AllocsPerRun(100, run); allocs != 0 {
+		t.Errorf("Disabled timers allocated %.1f times per run", allocs)
+	}
+}
+
+func TestDisabledContextAllocations(t *testing.T) {
+	ctx := NewDisabledContext(NewContext(context.Background()))
+	if allocs := testing.AllocsPerRun(100, func() { instrumented(ctx) }); allocs != 0 {
+		t.Errorf("Disabled context allocated %.1f times per run", allocs)
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	ctx := NewContext(context.Background())
+	SetEnabled(false)
+	if Enabled() {
+		t.Error("Timers still enabled")
+	}
+	if From(ctx).Enabled() {
+		t.Error("From() returned an enabled TimerSet while disabled")
+	}
+	From(ctx).New("disabled").Start().Stop()
+	SetEnabled(true)
+	if !Enabled() || !From(ctx).Enabled() {
+		t.Error("Timers not enabled again")
+	}
+	if From(ctx).Find("disabled") != nil {
+		t.Error("Timer was created while disabled")
+	}
+}
+
+func TestDisabledContext(t *testing.T) {
+	ctx := NewDisabledContext(NewContext(context.Background()))
+	s := From(ctx)
+	if s.Enabled() {
+		t.Fatal("Disabled context has an enabled TimerSet")
+	}
+	if NewContext(ctx) != ctx {
+		t.Error("NewContext() created a new context from a disabled one")
+	}
+	child, timer := NewContextWithTimer(ctx, "child")
+	if child != ctx || timer != noopTimer {
+		t.Error("NewContextWithTimer() created a new context and timer from a disabled one")
+	}
+	ran := false
+	s.Wrap(ctx, "wrap", func(c context.Context) {
+		ran = true
+		if c != ctx {
+			t.Error("Wrap() created a new context from a disabled one")
+		}
+	})
+	if !ran {
+		t.Error("Wrap() didn't run the function")
+	}
+
+	timer = s.New("timer").Start().Tag("tag").Attr("a", 1).Fail(errBoom).Lap("lap").Event("event").Add("rows", 1).Budget(1).Pause().Resume().Stop()
+	if timer.IsRunning() || timer.Duration() != 0 || len(timer.Tags()) != 0 || len(timer.Attrs()) != 0 ||
+		timer.Failed() || len(timer.Laps()) != 0 || len(timer.Events()) != 0 || len(timer.Counters()) != 0 || timer.budget != 0 {
+		t.Error("Disabled timer was changed")
+	}
+	if s.Mark("mark") != noopTimer {
+		t.Error("Mark() created a timer in a disabled TimerSet")
+	}
+	s.SetClock(NewManualClock(time.Now())).SetLimits(Limits{MaxTimers: 1}).SetBudget("timer", 1).OnOverBudget(func(Timer) {}).SetDebug(true)
+	if s.Clock() != (realClock{}) || s.Limits() != (Limits{}) || s.budgets != nil || s.onOverBudget != nil || s.debug {
+		t.Error("Disabled TimerSet was changed")
+	}
+	other := newSet()
+	other.New("other")
+	s.Merge(other, GraftOptions{})
+	timer.Attach(other, GraftOptions{})
+	if len(s.All()) != 0 || len(timer.Children()) != 0 || len(other.All()) != 1 {
+		t.Error("Timers were grafted into a disabled TimerSet")
+	}
+	if err := json.Unmarshal([]byte(`[{"name":"x"}]`), s); err != nil || len(s.All()) != 0 {
+		t.Error("Disabled TimerSet was unmarshalled into")
+	}
+	if err := json.Unmarshal([]byte(`{"name":"x"}`), timer); err != nil || timer.name != "" {
+		t.Error("Disabled Timer was unmarshalled into")
+	}
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	if _, found := response.Result().Header["Server-Timing"]; found {
+		t.Error("Disabled TimerSet added a Server-Timing header")
+	}
+}
+
+func BenchmarkEnabled(b *testing.B) {
+	ctx := NewContext(context.Background())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		instrumented(ctx)
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	SetEnabled(false)
+	defer SetEnabled(true)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		instrumented(ctx)
+	}
+}
+
+func BenchmarkDisabledContext(b *testing.B) {
+	ctx := NewDisabledContext(context.Background())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		instrumented(ctx)
+	}
+}
@@ -0,0 +1,50 @@
+package timers
+
+import "context"
+
+// Times fn with a new timer in the ctx's TimerSet, like Wrap, and returns what fn returns. fn is
+// given a context with a child TimerSet under the timer, so the timers it creates are grouped
+// under it. If fn returns an error it is recorded on the timer (see Timer.Fail).
+//  user, err := timers.Do(ctx, "lookup user", func(ctx context.Context) (*User, error) {
+//      return db.LookupUser(ctx, id)
+//  })
+func Do[T any](ctx context.Context, name string, fn func(context.Context) (T, error)) (T, error) {
+	newCtx, t := From(ctx).wrap(ctx, name)
+	t.Start()
+	v, err := fn(newCtx)
+	t.StopWithError(err)
+	return v, err
+}
+
+// Like Do, for functions that only return an error.
+//  err := timers.DoErr(ctx, "save user", func(ctx context.Context) error {
+//      return db.SaveUser(ctx, user)
+//  })
+func DoErr(ctx context.Context, name string, fn func(context.Context) error) error {
+	newCtx, t := From(ctx).wrap(ctx, name)
+	t.Start()
+	err := fn(newCtx)
+	t.StopWithError(err)
+	return err
+}
+
+// Like Timer.Measure, but returns what fn returns, recording the error on the timer. This doesn't
+// create a context and TimerSet, see Do.
+//  body, err := timers.Measure(timers.From(ctx).New("read body"), func() ([]byte, error) {
+//      return io.ReadAll(r.Body)
+//  })
+func Measure[T any](t *Timer, fn func() (T, error)) (T, error) {
+	t.Start()
+	v, err := fn()
+	t.StopWithError(err)
+	return v, err
+}
+
+// Like Measure, for functions that only return an error, which is recorded on the timer.
+//  err := From(ctx).New("flush").MeasureErr(w.Flush)
+func (t *Timer) MeasureErr(fn func() error) error {
+	t.Start()
+	err := fn()
+	t.StopWithError(err)
+	return err
+}
@@ -0,0 +1,85 @@
+package timers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock)
+	v, err := Do(ctx, "lookup", func(ctx context.Context) (string, error) {
+		From(ctx).New("query").Start()
+		clock.Advance(time.Millisecond)
+		From(ctx).Find("query").Stop()
+		return "found", nil
+	})
+	if v != "found" || err != nil {
+		t.Errorf("Do() returned %q, %v", v, err)
+	}
+	timer := s.Find("lookup")
+	if timer == nil || timer.IsRunning() || timer.Duration() != time.Millisecond || timer.Failed() {
+		t.Fatalf("Do() didn't time the call: %v", timer)
+	}
+	if children := timer.Children(); len(children) != 1 || children[0].name != "query" {
+		t.Errorf("Do() didn't put the timers created by the call under its timer: %v", children)
+	}
+
+	v, err = Do(ctx, "fail", func(ctx context.Context) (string, error) {
+		return "", errBoom
+	})
+	if v != "" || err != errBoom {
+		t.Errorf("Do() returned %q, %v", v, err)
+	}
+	if timer := s.Find("fail"); timer.IsRunning() || timer.Err() != errBoom {
+		t.Errorf("Do() didn't record the error: %s", timer.copy())
+	}
+}
+
+func TestDoErr(t *testing.T) {
+	ctx := NewContext(context.Background())
+	err := DoErr(ctx, "save", func(ctx context.Context) error {
+		From(ctx).New("write").Start().Stop()
+		return errBoom
+	})
+	timer := From(ctx).Find("save")
+	if err != errBoom || timer.IsRunning() || timer.Err() != errBoom {
+		t.Errorf("DoErr() returned %v, timer %s", err, timer.copy())
+	}
+	if From(ctx).FindPath("save/write") == nil {
+		t.Error("DoErr() didn't create a child TimerSet")
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	s := newSet()
+	n, err := Measure(s.New("count"), func() (int, error) { return 3, nil })
+	if n != 3 || err != nil || s.Find("count").IsRunning() || s.Find("count").Failed() {
+		t.Errorf("Measure() returned %d, %v", n, err)
+	}
+	_, err = Measure(s.New("fail"), func() (int, error) { return 0, errBoom })
+	if err != errBoom || s.Find("fail").Err() != errBoom {
+		t.Errorf("Measure() didn't record the error: %v", err)
+	}
+	err = s.New("flush").MeasureErr(func() error { return errBoom })
+	if err != errBoom || s.Find("flush").IsRunning() || s.Find("flush").Err() != errBoom {
+		t.Errorf("MeasureErr() didn't record the error: %v", err)
+	}
+}
+
+func TestDoOrigin(t *testing.T) {
+	ctx := NewContext(context.Background())
+	From(ctx).SetDebug(true)
+	origin := nextLine()
+	DoErr(ctx, "do", func(ctx context.Context) error { return nil })
+	wrapped := nextLine()
+	From(ctx).Wrap(ctx, "wrap", func(ctx context.Context) {})
+	if got := From(ctx).Find("do").Origin(); got != origin {
+		t.Errorf("Do() recorded the origin %s, not %s", got, origin)
+	}
+	if got := From(ctx).Find("wrap").Origin(); got != wrapped {
+		t.Errorf("Wrap() recorded the origin %s, not %s", got, wrapped)
+	}
+}
@@ -0,0 +1,126 @@
+package timers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// An Event is a named instant inside a single timer, such as "first byte received". Unlike a
+// Lap, it has no duration.
+type Event struct {
+	Name   string
+	Time   time.Time
+	Offset time.Duration // Time from the start of the timer
+}
+
+// Records a named instant on the timer. Events are only recorded while the timer is running,
+// otherwise this does nothing.
+//  t := timers.From(ctx).New("fetch").Start()
+//  resp := send(req)
+//  t.Event("first byte")
+//  read(resp)
+//  t.Stop()
+func (t *Timer) Event(name string) *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
+	if !t.running() {
+		return t
+	}
+	// Read under the lock, so events recorded by different go routines are in order
+	now := t.now()
+	t.events = append(t.events, Event{Name: name, Time: now, Offset: now.Sub(t.start)})
+	return t
+}
+
+// Returns a copy of the events recorded on the timer, in order.
+func (t *Timer) Events() []Event {
+	t.lock()
+	defer t.unlock()
+	events := make([]Event, len(t.events))
+	copy(events, t.events)
+	return events
+}
+
+// Records a named instant in the TimerSet, such as "cache miss" or "retry scheduled". A mark is
+// a timer that starts and stops at the same moment, so it is returned by All() and AllDeep(), and
+// exported, alongside the other timers, with a duration of zero.
+// Name is a format string (like Printf)
+func (s *TimerSet) Mark(name string, a ...interface{}) *Timer {
+	if s.readOnly() {
+		return noopTimer
+	}
+	t := newTimer(fmt.Sprintf(name, a...))
+	t.clock = s.Clock()
+	t.start = t.now()
+	t.stopped = true
+	t.mark = true
+	return s.add(t)
+}
+
+// Returns true if the timer is a mark, see TimerSet.Mark()
+func (t *Timer) IsMark() bool {
+	t.lock()
+	defer t.unlock()
+	return t.mark
+}
+
+// Formats a list of events for Timer.String()
+func fmtEvents(events []Event) string {
+	str := make([]string, len(events))
+	for i, e := range events {
+		str[i] = fmt.Sprintf("%s@%.3fms", e.Name, durationMs(e.Offset))
+	}
+	return strings.Join(str, ",")
+}
+
+// Formats a list of events for the Server-Timing header, as a query string of event name to
+// offset from the start of the timer in milliseconds, eg: first+byte=12.500
+func fmtEventsHeader(events []Event) string {
+	str := make([]string, len(events))
+	for i, e := range events {
+		str[i] = fmt.Sprintf("%s=%.3f", url.QueryEscape(e.Name), durationMs(e.Offset))
+	}
+	return strings.Join(str, "&")
+}
+
+// Marshaling type of the first JSON schema, in milliseconds
+type marshalEvent struct {
+	Name   string  `json:"name"`
+	Offset float64 `json:"offset"`
+}
+
+// The time of the event is restored from the start of the timer it belongs to.
+func (me marshalEvent) toEvent(start time.Time) Event {
+	offset := time.Duration(me.Offset * float64(time.Millisecond))
+	return Event{
+		Name:   me.Name,
+		Time:   start.Add(offset),
+		Offset: offset,
+	}
+}
+
+// Marshaling type of the current JSON schema, in nanoseconds
+type marshalEventV2 struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+}
+
+func (e Event) toMarshalEventV2() marshalEventV2 {
+	return marshalEventV2{
+		Name:   e.Name,
+		Offset: int64(e.Offset),
+	}
+}
+
+func (me marshalEventV2) toEvent(start time.Time) Event {
+	return Event{
+		Name:   me.Name,
+		Time:   start.Add(time.Duration(me.Offset)),
+		Offset: time.Duration(me.Offset),
+	}
+}
@@ -0,0 +1,112 @@
+package timers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	timer := newSet().SetClock(clock).New("fetch")
+	timer.Event("before start")
+	timer.Start()
+	clock.Advance(1500 * time.Microsecond)
+	timer.Event("first byte")
+	clock.Advance(time.Millisecond)
+	timer.Event("last byte").Stop()
+	timer.Event("after stop")
+
+	events := timer.Events()
+	if len(events) != 2 {
+		t.Fatalf("Timer has %d events, not 2: %v", len(events), events)
+	}
+	if events[0].Name != "first byte" || events[0].Offset != 1500*time.Microsecond ||
+		!events[0].Time.Equal(clock.Now().Add(-time.Millisecond)) {
+		t.Errorf("First event was %+v", events[0])
+	}
+	if events[1].Name != "last byte" || events[1].Offset != 2500*time.Microsecond {
+		t.Errorf("Second event was %+v", events[1])
+	}
+	events[0].Name = "changed"
+	if timer.Events()[0].Name != "first byte" {
+		t.Error("Events() didn't return a copy")
+	}
+	expect := "fetch: 2.500ms events:(first byte@1.500ms,last byte@2.500ms)"
+	if str := timer.copy().String(); str != expect {
+		t.Errorf("String() was %q, expected %q", str, expect)
+	}
+}
+
+func TestMark(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	timer := s.New("request").Start()
+	clock.Advance(time.Millisecond)
+	mark := s.Mark("cache miss %d", 1)
+	clock.Advance(time.Millisecond)
+	timer.Stop()
+
+	if !mark.IsMark() || timer.IsMark() {
+		t.Error("IsMark() is wrong")
+	}
+	if !mark.start.Equal(clock.Now().Add(-time.Millisecond)) || mark.Duration() != 0 || mark.IsRunning() {
+		t.Errorf("Mark was not a zero duration instant: %s", mark.copy())
+	}
+	// Marks are instants, they can't be started, stopped or paused
+	mark.Start().Pause().Resume().Lap("lap").Event("event").Stop()
+	if mark.Duration() != 0 || len(mark.Laps()) != 0 || len(mark.Events()) != 0 {
+		t.Error("Mark was changed")
+	}
+	if all := s.All(); len(all) != 2 || all[1].name != "cache miss 1" {
+		t.Errorf("Mark isn't in All(): %v", all)
+	}
+	if str := mark.copy().String(); str != "cache miss 1: Mark" {
+		t.Errorf("Mark's String() was %q", str)
+	}
+	if path := s.CriticalPath(); !sameNames(names(path), "request") {
+		t.Errorf("Critical path was %v", names(path))
+	}
+}
+
+func TestMarkAndEventExport(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	timer := s.New("fetch").Start()
+	s.Mark("cache miss")
+	clock.Advance(1500 * time.Microsecond)
+	timer.Event("first byte").Stop()
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"schema":2,"time":1644884400001500000,"timers":[` +
+		`{"id":1,"parent":0,"name":"fetch","state":"stopped","start":1644884400000000000,"duration":1500000,` +
+		`"events":[{"name":"first byte","offset":1500000}]},` +
+		`{"id":2,"parent":0,"name":"cache miss","state":"stopped","start":1644884400000000000,"duration":0,"mark":true}]}`
+	if string(b) != expect {
+		t.Errorf("JSON was\n%s\nexpected\n%s", b, expect)
+	}
+	var imported TimerSet
+	if err := json.Unmarshal(b, &imported); err != nil {
+		t.Fatal(err)
+	}
+	mark := imported.Find("cache miss")
+	if !mark.IsMark() || mark.Duration() != 0 {
+		t.Errorf("Imported mark was %s", mark.copy())
+	}
+	events := imported.Find("fetch").Events()
+	if len(events) != 1 || events[0] != timer.Events()[0] {
+		t.Errorf("Imported events were %+v, not %+v", events, timer.Events())
+	}
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.Contains(header, `;events="first+byte=1.500"`) || !strings.Contains(header, `cache_miss;descr="cache miss";dur=0.000;start=1644884400000;parent=0;id=2;mark=1`) {
+		t.Errorf("Header doesn't include the mark and events: %s", header)
+	}
+}
@@ -5,18 +5,16 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/zafnz/go-timers"
 )
 
-func otherMajorWork(ctx context.Context, wg *sync.WaitGroup) {
+func otherMajorWork(ctx context.Context) error {
 	fmt.Println("Some other major work starting")
 	time.Sleep(time.Duration(rand.Intn(1000) * int(time.Millisecond)))
 	fmt.Println("Some other major work finished")
-	wg.Done()
-
+	return nil
 }
 func moreMajorWork(ctx context.Context) {
 	fmt.Println("more major work starting")
@@ -46,14 +44,13 @@ func main() {
 		t.Start()
 		defer t.Stop()
 
-		wg := sync.WaitGroup{}
+		g := timers.NewGroup(ctx)
 		for i := 0; i < 2; i++ {
-			wg.Add(1)
-			go timers.From(ctx).Wrap(ctx, "majorWork", func(ctx context.Context) {
-				otherMajorWork(ctx, &wg)
-			})
+			g.Go("majorWork", otherMajorWork)
+		}
+		if err := g.Wait(); err != nil {
+			fmt.Println("Major work failed:", err)
 		}
-		wg.Wait()
 		fmt.Println("All major work complete")
 	}()
 
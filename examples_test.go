@@ -148,31 +148,58 @@ func ExampleTimerSet_Wrap() {
 
 }
 
+func ExampleDo() {
+	ctx := timers.NewContext(context.Background())
+	rows, err := timers.Do(ctx, "query", func(ctx context.Context) (int, error) {
+		timers.From(ctx).New("connect").Start().Stop()
+		return 3, nil
+	})
+	fmt.Println(rows, err, timers.From(ctx).Find("query").Failed())
+	// Output: 3 <nil> false
+}
+
 func ExampleTimerSet_MarshalJSON() {
 	ctx := timers.NewContext(context.Background())
-	timers.From(ctx).New("Timer 1")
-	timers.From(ctx).New("Timer 1")
-	timers.From(ctx).New("Timer 1")
+	clock := timers.NewManualClock(time.Unix(1644884400, 0))
+	timers.From(ctx).SetClock(clock)
+	timers.From(ctx).New("Timer 1").Start()
+	clock.Advance(2 * time.Millisecond)
+	timers.From(ctx).Find("Timer 1").Stop()
+	timers.From(ctx).New("Timer 2").Start()
+	timers.From(ctx).New("Timer 3")
+	clock.Advance(time.Millisecond)
 	bytes, _ := json.MarshalIndent(timers.From(ctx), "", " ")
 	fmt.Print(string(bytes))
 	// Output:
-	//[
-	//  {
-	//   "name": "Timer 1",
-	//   "start": 0,
-	//   "duration": 0
-	//  },
-	//  {
-	//   "name": "Timer 1",
-	//   "start": 0,
-	//   "duration": 0
-	//  },
-	//  {
-	//   "name": "Timer 1",
-	//   "start": 0,
-	//   "duration": 0
-	//  }
-	// ]
+	//{
+	//  "schema": 2,
+	//  "time": 1644884400003000000,
+	//  "timers": [
+	//   {
+	//    "id": 1,
+	//    "parent": 0,
+	//    "name": "Timer 1",
+	//    "state": "stopped",
+	//    "start": 1644884400000000000,
+	//    "duration": 2000000
+	//   },
+	//   {
+	//    "id": 2,
+	//    "parent": 0,
+	//    "name": "Timer 2",
+	//    "state": "running",
+	//    "start": 1644884400002000000,
+	//    "duration": 1000000
+	//   },
+	//   {
+	//    "id": 3,
+	//    "parent": 0,
+	//    "name": "Timer 3",
+	//    "state": "notStarted",
+	//    "duration": 0
+	//   }
+	//  ]
+	// }
 }
 
 func ExampleNew() {
@@ -183,3 +210,79 @@ func ExampleNew() {
 	// Output:
 	// Example: Running
 }
+
+func ExampleTimer_Attr() {
+	timer := timers.From(context.Background()).New("db query")
+	timer.Attr("rows", 1200).Attr("cached", false).Attr("table", "users")
+	for _, attr := range timer.Attrs() {
+		fmt.Println(attr)
+	}
+	// Output:
+	// rows=1200
+	// cached=false
+	// table=users
+}
+
+func lookupInventory(ctx context.Context, item string) (count int, err error) {
+	defer timers.From(ctx).New("inventory lookup").Start().StopErr(&err)
+	if item == "" {
+		return 0, fmt.Errorf("no item given")
+	}
+	return 42, nil
+}
+
+func ExampleTimer_StopErr() {
+	ctx := timers.NewContext(context.Background())
+	lookupInventory(ctx, "")
+	fmt.Println(timers.From(ctx).Find("inventory lookup").Err())
+	// Output:
+	// no item given
+}
+
+func ExampleTimer_Lap() {
+	ctx := timers.NewContext(context.Background())
+	t := timers.From(ctx).New("fetch").Start()
+	// connect()
+	t.Lap("connect")
+	// send()
+	t.Lap("send")
+	// read()
+	t.Lap("read").Stop()
+	for _, lap := range t.Laps() {
+		fmt.Println(lap.Name)
+	}
+	// Output:
+	// connect
+	// send
+	// read
+}
+
+func ExampleTimer_Pause() {
+	clock := timers.NewManualClock(time.Now())
+	ctx := timers.NewContext(context.Background())
+	timers.From(ctx).SetClock(clock)
+
+	t := timers.From(ctx).New("our code").Start()
+	clock.Advance(10 * time.Millisecond) // Our code
+	t.Pause()
+	clock.Advance(50 * time.Millisecond) // Waiting on someone else
+	t.Resume()
+	clock.Advance(10 * time.Millisecond) // Our code
+	t.Stop()
+	fmt.Println(t.Duration(), t.ActiveDuration())
+	// Output:
+	// 70ms 20ms
+}
+
+func ExampleManualClock() {
+	clock := timers.NewManualClock(time.Unix(1644884400, 0))
+	ctx := timers.NewContext(context.Background())
+	timers.From(ctx).SetClock(clock)
+
+	t := timers.From(ctx).New("api call").Start()
+	clock.Advance(5 * time.Millisecond)
+	t.Stop()
+	fmt.Println(timers.From(ctx))
+	// Output:
+	// api call: 5.000ms
+}
@@ -0,0 +1,90 @@
+package timers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Returns a copy of every timer in the tree (this TimerSet and all child TimerSets) with the
+// provided name. Like AllDeep(), these are copies, not the original timers.
+func (s *TimerSet) FindAll(name string) []*Timer {
+	return s.filter(func(t *Timer) bool {
+		return t.name == name
+	})
+}
+
+// Returns a copy of every timer in the tree whose name matches the glob pattern. A '*' matches
+// any run of characters (including none), and a '?' matches any single character. Everything
+// else must match exactly.
+//  timers.From(ctx).FindGlob("db *")
+func (s *TimerSet) FindGlob(pattern string) []*Timer {
+	return s.FindRegexp(globToRegexp(pattern))
+}
+
+// Returns a copy of every timer in the tree whose name matches the regular expression.
+func (s *TimerSet) FindRegexp(re *regexp.Regexp) []*Timer {
+	return s.filter(func(t *Timer) bool {
+		return re.MatchString(t.name)
+	})
+}
+
+// Returns a copy of every timer in the tree that has the provided tag.
+func (s *TimerSet) FilterByTag(tag string) []*Timer {
+	return s.filter(func(t *Timer) bool {
+		for _, tt := range t.tags {
+			if tt == tag {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Returns the timer at the provided path, or nil if there isn't one. A path is the names of
+// timers separated by '/', where each timer after the first is in the TimerSet under the
+// previous one (created with NewContextWithTimer or Wrap). As with Find, if more than one timer
+// has the same name the first is used, and the original timer is returned, not a copy.
+//  timers.From(ctx).FindPath("Request/db/query")
+func (s *TimerSet) FindPath(path string) *Timer {
+	set := s
+	var t *Timer
+	for _, name := range strings.Split(path, "/") {
+		if set == nil {
+			return nil
+		}
+		if t = set.Find(name); t == nil {
+			return nil
+		}
+		set = t.sub()
+	}
+	return t
+}
+
+// Returns copies of all timers in the tree for which fn returns true.
+func (s *TimerSet) filter(fn func(*Timer) bool) []*Timer {
+	var timers []*Timer
+	for _, t := range s.AllDeep() {
+		if fn(t) {
+			timers = append(timers, t)
+		}
+	}
+	return timers
+}
+
+// Converts a glob pattern to an anchored regular expression.
+func globToRegexp(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
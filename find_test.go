@@ -0,0 +1,117 @@
+package timers
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+// Builds a tree:
+//  Request
+//    db
+//      query
+//      query
+//    cache lookup (tag: cache)
+//  db
+//  db replica (tag: cache)
+func findTree() context.Context {
+	ctx := NewContext(context.Background())
+	req, _ := NewContextWithTimer(ctx, "Request")
+	From(req).Wrap(req, "db", func(ctx context.Context) {
+		From(ctx).New("query").Start().Stop()
+		From(ctx).New("query")
+	})
+	From(req).New("cache lookup").Tag("cache")
+	From(ctx).New("db")
+	From(ctx).New("db replica").Tag("replica").Tag("cache")
+	return ctx
+}
+
+func names(timers []*Timer) []string {
+	var str []string
+	for _, t := range timers {
+		str = append(str, t.name)
+	}
+	return str
+}
+
+func sameNames(a []string, b ...string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFindAll(t *testing.T) {
+	s := From(findTree())
+	found := s.FindAll("query")
+	if len(found) != 2 {
+		t.Fatalf("Expected 2 query timers, found %d", len(found))
+	}
+	if found[0] == s.FindPath("Request/db/query") {
+		t.Error("FindAll() returned the original timer, not a copy")
+	}
+	if len(s.FindAll("db")) != 2 {
+		t.Error("Didn't find db at both depths")
+	}
+	if len(s.FindAll("missing")) != 0 {
+		t.Error("Found a missing timer")
+	}
+}
+
+func TestFindGlob(t *testing.T) {
+	s := From(findTree())
+	if n := names(s.FindGlob("db*")); !sameNames(n, "db", "db replica", "db") {
+		t.Errorf("db* found %v", n)
+	}
+	if n := names(s.FindGlob("?b")); !sameNames(n, "db", "db") {
+		t.Errorf("?b found %v", n)
+	}
+	if n := names(s.FindGlob("*e*y")); !sameNames(n, "query", "query") {
+		t.Errorf("*e*y found %v", n)
+	}
+	if n := names(s.FindGlob("d.")); len(n) != 0 {
+		t.Errorf("d. should not be treated as a regular expression, found %v", n)
+	}
+}
+
+func TestFindRegexp(t *testing.T) {
+	s := From(findTree())
+	if n := names(s.FindRegexp(regexp.MustCompile(`^(query|cache)`))); !sameNames(n, "cache lookup", "query", "query") {
+		t.Errorf("Regexp found %v", n)
+	}
+}
+
+func TestFilterByTag(t *testing.T) {
+	s := From(findTree())
+	if n := names(s.FilterByTag("cache")); !sameNames(n, "db replica", "cache lookup") {
+		t.Errorf("Tag cache found %v", n)
+	}
+	if n := names(s.FilterByTag("missing")); len(n) != 0 {
+		t.Errorf("Tag missing found %v", n)
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	s := From(findTree())
+	query := s.FindPath("Request/db/query")
+	if query == nil {
+		t.Fatal("Didn't find Request/db/query")
+	}
+	if !query.stopped {
+		t.Error("FindPath() didn't return the first query timer")
+	}
+	if s.FindPath("Request") != s.Find("Request") {
+		t.Error("FindPath() with a single name isn't the same as Find()")
+	}
+	for _, path := range []string{"Request/missing", "db/query", "Request/db/query/deeper", ""} {
+		if s.FindPath(path) != nil {
+			t.Errorf("Found missing path %s", path)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/zafnz/go-timers
 
-go 1.17
+go 1.18
 
 require github.com/felixge/httpsnoop v1.0.3
@@ -0,0 +1,131 @@
+package timers
+
+import (
+	"context"
+	"sync"
+)
+
+// A Group runs functions in go routines, each timed by its own timer with a child TimerSet, like
+// Wrap, and waits for them to finish. It is like errgroup.Group: the first error returned is kept
+// and cancels the context the functions are given.
+//
+// Each timer also records the lane it ran in. A lane is like a worker: at any one time there is
+// at most one function running in each lane, and a function gets the lowest lane that is free when
+// it is started. So timers in different lanes ran in parallel, and the number of lanes used is how
+// many ran at once. Lanes are numbered from 1, see Timer.Lane.
+//  g := timers.NewGroup(ctx)
+//  for _, id := range ids {
+//      id := id
+//      g.Go("fetch", func(ctx context.Context) error {
+//          return fetch(ctx, id)
+//      })
+//  }
+//  err := g.Wait()
+type Group struct {
+	ctx    context.Context
+	cancel func()
+	wg     sync.WaitGroup
+	sem    chan struct{} // Limits the running go routines, if SetLimit was called
+	mu     sync.Mutex
+	lanes  []bool // Lanes in use
+	err    error
+}
+
+// Returns a new Group, which creates its timers in the TimerSet in ctx.
+func NewGroup(ctx context.Context) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Limits the number of functions running at once to n, so Go blocks until a lane is free. An n
+// of zero or less removes the limit, rather than blocking every call to Go. The limit must not be
+// changed while functions are running.
+func (g *Group) SetLimit(n int) *Group {
+	if n <= 0 {
+		g.sem = nil
+	} else {
+		g.sem = make(chan struct{}, n)
+	}
+	return g
+}
+
+// Calls fn in a new go routine, timed by a new timer with a child TimerSet in fn's context, as
+// with Wrap. If fn returns an error it is recorded on the timer, and the first one is returned
+// by Wait.
+func (g *Group) Go(name string, fn func(context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	ctx, t := From(g.ctx).wrap(g.ctx, name)
+	lane := g.takeLane()
+	t.setLane(lane)
+	g.wg.Add(1)
+	go func() {
+		defer g.done(lane)
+		t.Start()
+		err := fn(ctx)
+		t.StopWithError(err)
+		if err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// Waits for all the functions to return, and returns the first error, if any.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+// Returns the lowest free lane, numbered from 1.
+func (g *Group) takeLane() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, busy := range g.lanes {
+		if !busy {
+			g.lanes[i] = true
+			return i + 1
+		}
+	}
+	g.lanes = append(g.lanes, true)
+	return len(g.lanes)
+}
+
+func (g *Group) done(lane int) {
+	g.mu.Lock()
+	g.lanes[lane-1] = false
+	g.mu.Unlock()
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err == nil {
+		g.err = err
+		g.cancel()
+	}
+}
+
+// Returns the lane the timer ran in, if it was run by a Group, or 0 if it wasn't. Timers in
+// different lanes of the same Group ran in parallel, see Group.
+func (t *Timer) Lane() int {
+	t.lock()
+	defer t.unlock()
+	return t.lane
+}
+
+func (t *Timer) setLane(lane int) {
+	if t.readOnly() {
+		return
+	}
+	t.lock()
+	defer t.unlock()
+	t.lane = lane
+}
@@ -0,0 +1,136 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupLanes(t *testing.T) {
+	ctx := NewContext(context.Background())
+	g := NewGroup(ctx).SetLimit(2)
+	release := []chan struct{}{make(chan struct{}), make(chan struct{}), make(chan struct{})}
+	for i, name := range []string{"a", "b", "c"} {
+		ch := release[i]
+		if i == 2 {
+			// Lets "a" finish, so "c" takes its lane
+			close(release[0])
+		}
+		g.Go(name, func(ctx context.Context) error {
+			From(ctx).New("child").Start().Stop()
+			<-ch
+			return nil
+		})
+	}
+	close(release[1])
+	close(release[2])
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait() returned %v", err)
+	}
+	for name, lane := range map[string]int{"a": 1, "b": 2, "c": 1} {
+		timer := From(ctx).Find(name)
+		if timer.Lane() != lane || timer.IsRunning() || timer.Duration() == 0 {
+			t.Errorf("%s ran in lane %d, not %d", timer.copy(), timer.Lane(), lane)
+		}
+		if From(ctx).FindPath(name+"/child") == nil {
+			t.Errorf("%s doesn't have a child TimerSet", name)
+		}
+	}
+	if lane := From(ctx).New("not in a group").Lane(); lane != 0 {
+		t.Errorf("Timer not run by a group has lane %d", lane)
+	}
+}
+
+func TestGroupNoLimit(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		ctx := NewContext(context.Background())
+		g := NewGroup(ctx).SetLimit(2).SetLimit(n)
+		release := make(chan struct{})
+		for _, name := range []string{"a", "b", "c"} {
+			g.Go(name, func(ctx context.Context) error {
+				<-release
+				return nil
+			})
+		}
+		// All three are running at once, or Go would have blocked
+		close(release)
+		if err := g.Wait(); err != nil {
+			t.Fatalf("Wait() returned %v", err)
+		}
+		if lane := From(ctx).Find("c").Lane(); lane != 3 {
+			t.Errorf("With a limit of %d, c ran in lane %d, not 3", n, lane)
+		}
+	}
+}
+
+func TestGroupError(t *testing.T) {
+	ctx := NewContext(context.Background())
+	g := NewGroup(ctx)
+	failed := make(chan struct{})
+	g.Go("fail", func(ctx context.Context) error {
+		defer close(failed)
+		return errBoom
+	})
+	g.Go("cancelled", func(ctx context.Context) error {
+		<-failed
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err := g.Wait(); err != errBoom {
+		t.Errorf("Wait() returned %v, not the first error", err)
+	}
+	if timer := From(ctx).Find("fail"); timer.Err() != errBoom {
+		t.Errorf("The error wasn't recorded on the timer: %s", timer.copy())
+	}
+	if timer := From(ctx).Find("cancelled"); timer.Err() != context.Canceled {
+		t.Errorf("The context wasn't cancelled by the error: %s", timer.copy())
+	}
+}
+
+func TestGroupDisabled(t *testing.T) {
+	SetEnabled(false)
+	defer SetEnabled(true)
+	g := NewGroup(context.Background())
+	ran := false
+	g.Go("work", func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	if err := g.Wait(); err != nil || !ran {
+		t.Errorf("Group didn't run the function when disabled: %v", err)
+	}
+}
+
+func TestLaneExported(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	timer := s.New("work").Start()
+	timer.setLane(2)
+	clock.Advance(time.Millisecond)
+	timer.Stop()
+
+	if str := timer.copy().String(); str != "work: 1.000ms lane:(2)" {
+		t.Errorf("String() was %s", str)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"schema":2,"time":1644884400001000000,"timers":[{"id":1,"parent":0,"name":"work","state":"stopped",` +
+		`"start":1644884400000000000,"duration":1000000,"lane":2}]}`
+	if string(b) != expect {
+		t.Errorf("JSON was\n%s\nexpected\n%s", b, expect)
+	}
+	var imported TimerSet
+	if err := json.Unmarshal(b, &imported); err != nil || imported.Find("work").Lane() != 2 {
+		t.Errorf("Lane wasn't imported: %v", err)
+	}
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	if header := response.Result().Header.Get("Server-Timing"); !strings.HasSuffix(header, ";lane=2") {
+		t.Errorf("Header doesn't include the lane: %s", header)
+	}
+}
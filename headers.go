@@ -21,7 +21,10 @@ import (
 //      fmt.Fprintf(w, result)
 //  }
 func (s *TimerSet) AddHeader(w http.ResponseWriter) {
-	timers := s.AllDeep()
+	if s.disabled {
+		return
+	}
+	timers := s.Snapshot().flatTree(true)
 	allValues := make([]string, len(timers))
 	existing := make(map[string]struct{})
 	for idx, timer := range timers {
@@ -32,17 +35,56 @@ func (s *TimerSet) AddHeader(w http.ResponseWriter) {
 }
 
 func (t *Timer) fmtAsHeader(uniqName string) string {
+	var str string
 	if t.start.IsZero() {
-		return fmt.Sprintf("%s;descr=%s;dur=0;parent=%d;id=%d", uniqName, quotedString(t.name), t.parentId, t.id)
+		str = fmt.Sprintf("%s;descr=%s;dur=0;parent=%d;id=%d", uniqName, quotedString(t.name), t.parentId, t.id)
+	} else {
+		str = fmt.Sprintf("%s;descr=%s;dur=%.3f;start=%d;parent=%d;id=%d",
+			uniqName, quotedString(t.name), t.Milliseconds(), t.start.UnixMilli(), t.parentId, t.id)
+	}
+	if t.dropped > 0 {
+		str += fmt.Sprintf(";dropped=%d", t.dropped)
+	}
+	if t.mark {
+		str += ";mark=1"
+	}
+	if t.subtimer != nil && !t.start.IsZero() {
+		str += fmt.Sprintf(";self=%.3f", durationMs(t.selfDuration()))
+	}
+	if t.pauses > 0 {
+		str += fmt.Sprintf(";active=%.3f", t.ActiveMilliseconds())
+	}
+	if len(t.laps) > 0 {
+		str += ";laps=" + quotedString(fmtLapsHeader(t.laps))
+	}
+	if len(t.events) > 0 {
+		str += ";events=" + quotedString(fmtEventsHeader(t.events))
+	}
+	str += fmtCountersHeader("count", t.counters)
+	if t.hasChildCounters() {
+		str += fmtCountersHeader("total", t.totals())
 	}
-	return fmt.Sprintf("%s;descr=%s;dur=%.3f;start=%d;parent=%d;id=%d",
-		uniqName, quotedString(t.name), t.Milliseconds(), t.start.UnixMilli(), t.parentId, t.id)
+	if t.budget > 0 {
+		str += fmt.Sprintf(";budget=%.3f", durationMs(t.budget))
+	}
+	if t.overBudget {
+		str += ";over_budget=1"
+	}
+	if t.lane > 0 {
+		str += fmt.Sprintf(";lane=%d", t.lane)
+	}
+	if t.err != nil {
+		str += ";error=" + quotedString(t.err.Error())
+	}
+	return str
 }
 
+// Characters that can't be used in Server-Timing names
+var headerNamePattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
 // Returns a timer name that doesn't exist.
 func simplifyTimerName(existing map[string]struct{}, name string) string {
-	pattern := regexp.MustCompile(`[^A-Za-z0-9_]`)
-	name = pattern.ReplaceAllString(name, "_")
+	name = headerNamePattern.ReplaceAllString(name, "_")
 	if name == "" || name == "_" {
 		name = "timer"
 	}
@@ -59,7 +101,22 @@ func simplifyTimerName(existing map[string]struct{}, name string) string {
 	}
 }
 
-// returns a quoted string where existing quotes have been escaped
+// returns a quoted string where existing quotes and backslashes have been escaped. Control
+// characters can't be in a quoted string, so they are replaced with spaces.
 func quotedString(str string) string {
-	return fmt.Sprintf("\"%s\"", strings.ReplaceAll(str, "\"", "\\\""))
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range str {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' && r != '\t' || r == 0x7f:
+			b.WriteByte(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
 }
@@ -1,6 +1,7 @@
 package timers
 
 import (
+	"errors"
 	"fmt"
 	"net/http/httptest"
 	"strings"
@@ -44,3 +45,36 @@ func TestAddHeader(t *testing.T) {
 		t.Errorf("At least one timer wasn't id=2")
 	}
 }
+
+func TestAddHeaderEscaping(t *testing.T) {
+	s := newSet()
+	s.New("a \\ \"b\"").Start().StopWithError(errors.New(`bad "input, here" \" end`))
+	s.New("next").Start().Stop()
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.Contains(header, `descr="a \\ \"b\""`) {
+		t.Errorf("Name wasn't escaped: %s", header)
+	}
+	if !strings.Contains(header, `;error="bad \"input, here\" \\\" end",`) {
+		t.Errorf("Error wasn't escaped: %s", header)
+	}
+
+	// Splitting on the commas outside quoted strings, skipping escaped characters, gives each timer
+	var timers []string
+	inQuote, start := false, 0
+	for i := 0; i < len(header); i++ {
+		if header[i] == '\\' && inQuote {
+			i++
+		} else if header[i] == '"' {
+			inQuote = !inQuote
+		} else if header[i] == ',' && !inQuote {
+			timers = append(timers, header[start:i])
+			start = i + 1
+		}
+	}
+	timers = append(timers, header[start:])
+	if len(timers) != 2 || !strings.HasPrefix(strings.TrimSpace(timers[1]), "next;") {
+		t.Errorf("Header didn't split into the 2 timers: %q", timers)
+	}
+}
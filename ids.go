@@ -0,0 +1,80 @@
+package timers
+
+import "sync/atomic"
+
+// Hands out the timer IDs for a tree of TimerSets.
+type idCounter struct {
+	last int64
+}
+
+func (c *idCounter) next() int {
+	return int(atomic.AddInt64(&c.last, 1))
+}
+
+// Returns the timer's ID, which is given to it when it is created, and is unique within the
+// tree of TimerSets it was created in (see NewContext, NewContextWithTimer and Wrap). IDs start
+// at 1, and are used as the "id" and "parent" in the Server-Timing header, so a timer can be
+// referred to across exports. Timers grafted into a tree with Attach or Merge, or imported with
+// UnmarshalJSON, are given new IDs. Disabled timers, and timers dropped because of Limits, have
+// the ID 0.
+func (t *Timer) ID() int {
+	t.lock()
+	defer t.unlock()
+	return t.id
+}
+
+// Returns the ID of the timer this timer is under (the timer NewContextWithTimer or Wrap
+// created), or 0 if it is at the top of the tree.
+func (t *Timer) ParentID() int {
+	t.lock()
+	defer t.unlock()
+	return t.parentId
+}
+
+// Returns the set's ID counter, creating one if the set was not created from another. Caller
+// must hold the lock.
+func (s *TimerSet) idCounter() *idCounter {
+	if s.ids == nil {
+		s.ids = &idCounter{}
+	}
+	return s.ids
+}
+
+// Gives the timer its ID, as the newest timer in this set. Caller must hold the lock.
+func (s *TimerSet) assignID(t *Timer) {
+	t.ids = s.idCounter()
+	t.id = t.ids.next()
+	t.parentId = s.parentId
+	if t.subtimer != nil {
+		t.subtimer.mu.Lock()
+		t.subtimer.ids, t.subtimer.parentId = t.ids, t.id
+		t.subtimer.mu.Unlock()
+	}
+}
+
+// Gives the timers in this set, and all their children, new IDs from ids, as they have joined
+// a new tree under the timer with the ID parentId.
+func (s *TimerSet) adopt(ids *idCounter, parentId int) {
+	s.mu.Lock()
+	s.ids, s.parentId = ids, parentId
+	if s.dropped > 0 {
+		s.droppedId = ids.next()
+	}
+	timers := append([]*Timer(nil), s.timers...)
+	s.mu.Unlock()
+	adoptTimers(timers, ids, parentId)
+}
+
+// Like TimerSet.adopt, for timers that are being moved into a set.
+func adoptTimers(timers []*Timer, ids *idCounter, parentId int) {
+	for _, t := range timers {
+		t.lock()
+		t.ids = ids
+		t.id, t.parentId = ids.next(), parentId
+		id, sub := t.id, t.subtimer
+		t.unlock()
+		if sub != nil {
+			sub.adopt(ids, id)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIDs(t *testing.T) {
+	ctx := NewContext(context.Background())
+	first := From(ctx).New("first")
+	reqCtx, request := NewContextWithTimer(ctx, "request")
+	second := From(ctx).New("second")
+	child := From(reqCtx).New("child")
+	var wrapped *Timer
+	From(reqCtx).Wrap(reqCtx, "wrap", func(ctx context.Context) {
+		wrapped = From(ctx).New("wrapped")
+	})
+
+	expect := []struct {
+		timer        *Timer
+		id, parentId int
+	}{
+		{first, 1, 0},
+		{request, 2, 0},
+		{second, 3, 0},
+		{child, 4, 2},
+		{From(reqCtx).Find("wrap"), 5, 2},
+		{wrapped, 6, 5},
+	}
+	for _, e := range expect {
+		if e.timer.ID() != e.id || e.timer.ParentID() != e.parentId {
+			t.Errorf("%s has ID %d and parent %d, not %d and %d", e.timer.name, e.timer.ID(), e.timer.ParentID(), e.id, e.parentId)
+		}
+	}
+
+	// Exports don't renumber the timers
+	for i := 0; i < 2; i++ {
+		response := httptest.NewRecorder()
+		From(ctx).AddHeader(response)
+		header := response.Result().Header.Get("Server-Timing")
+		if !strings.Contains(header, `wrapped;descr="wrapped";dur=0;parent=5;id=6`) {
+			t.Errorf("Header doesn't use the timer's IDs: %s", header)
+		}
+	}
+	for _, c := range From(ctx).AllDeep() {
+		if c.name == "child" && (c.id != 4 || c.parentId != 2) {
+			t.Errorf("AllDeep() renumbered the timers: %d %d", c.id, c.parentId)
+		}
+	}
+	if noopTimer.ID() != 0 {
+		t.Error("Disabled timer has an ID")
+	}
+}
+
+func TestIDsGrafted(t *testing.T) {
+	ctx := NewContext(context.Background())
+	s := From(ctx)
+	s.New("a")
+	timer := s.New("b")
+
+	other := newSet()
+	other.New("c")
+	var worker TimerSet
+	if err := json.Unmarshal([]byte(`[{"name":"d","start":1,"duration":1,"children":[{"name":"e","start":1,"duration":1}]}]`), &worker); err != nil {
+		t.Fatal(err)
+	}
+	if e := worker.FindPath("d/e"); worker.Find("d").ID() != 1 || e.ID() != 2 || e.ParentID() != 1 {
+		t.Errorf("Imported timers weren't given IDs: %d %d", e.ID(), e.ParentID())
+	}
+
+	s.Merge(other, GraftOptions{})
+	timer.Attach(&worker, GraftOptions{})
+	seen := make(map[int]string)
+	for _, c := range s.AllDeep() {
+		if name, ok := seen[c.id]; ok || c.id == 0 {
+			t.Errorf("%s has the same ID as %s: %d", c.name, name, c.id)
+		}
+		seen[c.id] = c.name
+	}
+	if d := s.FindPath("b/d"); d.ParentID() != timer.ID() || s.FindPath("b/d/e").ParentID() != d.ID() {
+		t.Error("Attached timers don't have the right parent IDs")
+	}
+	if c := s.Find("c"); c.ID() != 3 || c.ParentID() != 0 {
+		t.Errorf("Merged timer has ID %d and parent %d", c.ID(), c.ParentID())
+	}
+}
+
+func TestDroppedID(t *testing.T) {
+	s := newSet().SetLimits(Limits{MaxTimers: 1})
+	s.New("kept")
+	dropped := s.New("dropped")
+	s.New("kept later")
+	if dropped.ID() != 0 {
+		t.Errorf("Dropped timer has the ID %d", dropped.ID())
+	}
+	if d := s.droppedTimer(); d.id != 2 {
+		t.Errorf("Dropped timer report has the ID %d", d.id)
+	}
+}
@@ -0,0 +1,426 @@
+package timers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// The version of the JSON written by MarshalJSON, which is its "schema" field.
+//
+// Schema 2 records everything about the timers, so that a marshal and unmarshal round trip
+// doesn't change them: times and durations are in nanoseconds (start times since the Unix epoch),
+// each timer has its id and parent id (see Timer.ID), and its state. The first schema had no
+// "schema" field, and is a list of timers with millisecond times and durations. UnmarshalJSON
+// reads both.
+const JSONSchema = 2
+
+// The states of a timer in the JSON
+const (
+	stateNotStarted = "notStarted"
+	stateRunning    = "running"
+	statePaused     = "paused"
+	stateStopped    = "stopped"
+)
+
+// Marshaling types for the current schema. A TimerSet is an object with the schema, the time the
+// timers were captured, and the list of timers. A single Timer has the schema and time with its
+// own fields.
+type marshalSetV2 struct {
+	Schema int              `json:"schema"`
+	Time   int64            `json:"time"`
+	Timers []marshalTimerV2 `json:"timers"`
+}
+
+type marshalOneTimerV2 struct {
+	Schema int   `json:"schema"`
+	Time   int64 `json:"time"`
+	marshalTimerV2
+}
+
+type marshalTimerV2 struct {
+	ID           int               `json:"id"`
+	Parent       int               `json:"parent"`
+	Name         string            `json:"name"`
+	State        string            `json:"state"`
+	Start        int64             `json:"start,omitempty"`
+	Duration     int64             `json:"duration"` // So far, if the timer is running
+	Active       *int64            `json:"active,omitempty"`
+	Pauses       int               `json:"pauses,omitempty"`
+	Mark         bool              `json:"mark,omitempty"`
+	Group        bool              `json:"group,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Attrs        []marshalAttr     `json:"attrs,omitempty"`
+	Error        *string           `json:"error,omitempty"`
+	Laps         []marshalLapV2    `json:"laps,omitempty"`
+	Events       []marshalEventV2  `json:"events,omitempty"`
+	Counters     *[]marshalCounter `json:"counters,omitempty"`
+	Totals       *[]marshalCounter `json:"totals,omitempty"` // Export only, see Totals
+	Budget       int64             `json:"budget,omitempty"`
+	OverBudget   bool              `json:"overBudget,omitempty"`
+	Self         *int64            `json:"self,omitempty"` // Export only, see SelfDuration
+	Lane         int               `json:"lane,omitempty"`
+	Origin       string            `json:"origin,omitempty"`
+	StoppedByAll bool              `json:"stoppedByAll,omitempty"`
+	Dropped      int               `json:"dropped,omitempty"`
+	Children     *[]marshalTimerV2 `json:"children,omitempty"`
+}
+
+// Returns the set's timers for the JSON. The set should be a snapshot, so that the timers are
+// consistent with each other.
+func (s *TimerSet) toMarshalTimersV2() ([]marshalTimerV2, error) {
+	src := s.All()
+	timers := make([]marshalTimerV2, len(src))
+	for i := range src {
+		mt, err := src[i].toMarshalTimerV2()
+		if err != nil {
+			return nil, err
+		}
+		timers[i] = mt
+	}
+	if d := s.droppedTimer(); d != nil {
+		mt, err := d.toMarshalTimerV2()
+		if err != nil {
+			return nil, err
+		}
+		timers = append(timers, mt)
+	}
+	return timers, nil
+}
+
+func (t *Timer) toMarshalTimerV2() (marshalTimerV2, error) {
+	mt := marshalTimerV2{
+		ID:           t.id,
+		Parent:       t.parentId,
+		Name:         t.name,
+		State:        stateStopped,
+		Duration:     int64(t.wallDuration()),
+		Pauses:       t.pauses,
+		Mark:         t.mark,
+		Group:        t.group,
+		Tags:         t.tags,
+		Counters:     toMarshalCounters(t.counters),
+		Budget:       int64(t.budget),
+		OverBudget:   t.overBudget,
+		Lane:         t.lane,
+		Origin:       t.origin,
+		StoppedByAll: t.stoppedByAll,
+		Dropped:      t.dropped,
+	}
+	if t.start.IsZero() {
+		mt.State = stateNotStarted
+	} else {
+		mt.Start = t.start.UnixNano()
+		if t.isPaused() {
+			mt.State = statePaused
+		} else if t.running() {
+			mt.State = stateRunning
+		}
+	}
+	if t.pauses > 0 {
+		active := int64(t.activeDuration())
+		mt.Active = &active
+	}
+	for _, a := range t.attrs {
+		ma, err := a.toMarshalAttr()
+		if err != nil {
+			return mt, err
+		}
+		mt.Attrs = append(mt.Attrs, ma)
+	}
+	if t.err != nil {
+		msg := t.err.Error()
+		mt.Error = &msg
+	}
+	for _, l := range t.laps {
+		mt.Laps = append(mt.Laps, l.toMarshalLapV2())
+	}
+	for _, e := range t.events {
+		mt.Events = append(mt.Events, e.toMarshalEventV2())
+	}
+	if t.hasChildCounters() {
+		mt.Totals = toMarshalCounters(t.totals())
+	}
+	if t.subtimer != nil {
+		if !t.start.IsZero() {
+			self := int64(t.selfDuration())
+			mt.Self = &self
+		}
+		children, err := t.subtimer.toMarshalTimersV2()
+		if err != nil {
+			return mt, err
+		}
+		mt.Children = &children
+	}
+	return mt, nil
+}
+
+// Turns the JSON back into a timer. Running and paused timers are frozen at the time the timers
+// were captured, like a Snapshot.
+func (t *Timer) fromMarshaledTimerV2(mt marshalTimerV2, captured Clock) error {
+	if t.mu == nil {
+		t.mu = &sync.Mutex{}
+	}
+	t.id, t.parentId = mt.ID, mt.Parent
+	t.name = mt.Name
+	if mt.State != stateNotStarted {
+		t.start = time.Unix(0, mt.Start)
+	}
+	switch mt.State {
+	case stateNotStarted:
+	case stateStopped:
+		t.stopped = true
+		t.duration = time.Duration(mt.Duration)
+	case stateRunning, statePaused:
+		t.clock = captured
+		if mt.State == stateRunning {
+			t.resumed = captured.Now()
+		}
+	default:
+		return fmt.Errorf("timer %s has unknown state %s", mt.Name, mt.State)
+	}
+	if mt.Active != nil {
+		t.active = time.Duration(*mt.Active)
+	}
+	t.pauses = mt.Pauses
+	t.mark = mt.Mark
+	t.group = mt.Group
+	t.tags = mt.Tags
+	for _, ma := range mt.Attrs {
+		a, err := ma.toAttr()
+		if err != nil {
+			return err
+		}
+		t.attrs = append(t.attrs, a)
+	}
+	t.err = errorFromMessage(mt.Error)
+	for _, ml := range mt.Laps {
+		t.laps = append(t.laps, ml.toLap())
+	}
+	for _, me := range mt.Events {
+		t.events = append(t.events, me.toEvent(t.start))
+	}
+	t.counters = fromMarshalCounters(mt.Counters)
+	t.budget = time.Duration(mt.Budget)
+	t.overBudget = mt.OverBudget
+	t.lane = mt.Lane
+	t.origin = mt.Origin
+	t.stoppedByAll = mt.StoppedByAll
+	t.dropped = mt.Dropped
+	if mt.Children != nil {
+		s := newSet()
+		s.parentId, s.attached = t.id, true
+		t.subtimer = s
+		s.timers = make([]*Timer, len(*mt.Children))
+		for i, child := range *mt.Children {
+			s.timers[i] = newTimer("")
+			if err := s.timers[i].fromMarshaledTimerV2(child, captured); err != nil {
+				return err
+			}
+		}
+		s.collectDropped()
+	}
+	return nil
+}
+
+// Reads the timers from either schema, see JSONSchema.
+func unmarshalTimers(data []byte) ([]*Timer, error) {
+	if !isJSONObject(data) {
+		var list []marshalTimer
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		list = nestTimers(list)
+		timers := make([]*Timer, len(list))
+		for i, mt := range list {
+			timers[i] = newTimer("")
+			if err := timers[i].fromMarshaledTimer(mt); err != nil {
+				return nil, err
+			}
+		}
+		return timers, nil
+	}
+	var ms marshalSetV2
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return nil, err
+	}
+	if err := checkSchema(ms.Schema); err != nil {
+		return nil, err
+	}
+	captured := &frozenClock{now: time.Unix(0, ms.Time)}
+	timers := make([]*Timer, len(ms.Timers))
+	for i, mt := range ms.Timers {
+		timers[i] = newTimer("")
+		if err := timers[i].fromMarshaledTimerV2(mt, captured); err != nil {
+			return nil, err
+		}
+	}
+	return timers, nil
+}
+
+// Reads a single timer from either schema.
+func (t *Timer) unmarshalTimer(data []byte) error {
+	var probe struct {
+		Schema *int `json:"schema"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+	if probe.Schema == nil {
+		var mt marshalTimer
+		if err := json.Unmarshal(data, &mt); err != nil {
+			return err
+		}
+		return t.fromMarshaledTimer(mt)
+	}
+	if err := checkSchema(*probe.Schema); err != nil {
+		return err
+	}
+	var mt marshalOneTimerV2
+	if err := json.Unmarshal(data, &mt); err != nil {
+		return err
+	}
+	return t.fromMarshaledTimerV2(mt.marshalTimerV2, &frozenClock{now: time.Unix(0, mt.Time)})
+}
+
+func checkSchema(schema int) error {
+	if schema != JSONSchema {
+		return fmt.Errorf("timers JSON has unsupported schema %d", schema)
+	}
+	return nil
+}
+
+// Returns true if the JSON is an object, rather than a list.
+func isJSONObject(data []byte) bool {
+	for _, c := range data {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return c == '{'
+	}
+	return false
+}
+
+// Nests the timers in a flat list, where each timer has its id and the id of its parent (like
+// the Server-Timing header), under their parents. Timers whose parent isn't in the list stay at
+// the top. Lists that are already nested are returned as they are.
+func nestTimers(list []marshalTimer) []marshalTimer {
+	index := make(map[int]int)
+	for i, mt := range list {
+		if mt.ID != 0 {
+			index[mt.ID] = i
+		}
+	}
+	children := make(map[int][]int)
+	for i, mt := range list {
+		if p, ok := index[mt.Parent]; ok && mt.Parent != 0 && p != i {
+			children[p] = append(children[p], i)
+		}
+	}
+	if len(children) == 0 {
+		return list
+	}
+	done := make([]bool, len(list))
+	var nest func(i int) marshalTimer
+	nest = func(i int) marshalTimer {
+		done[i] = true
+		mt := list[i]
+		for _, c := range children[i] {
+			if done[c] {
+				continue
+			}
+			var nested []marshalTimer
+			if mt.Children != nil {
+				nested = append(nested, *mt.Children...)
+			}
+			nested = append(nested, nest(c))
+			mt.Children = &nested
+		}
+		return mt
+	}
+	var top []marshalTimer
+	for i, mt := range list {
+		if p, ok := index[mt.Parent]; !ok || mt.Parent == 0 || p == i {
+			top = append(top, nest(i))
+		}
+	}
+	// Timers in a loop of parents have no way to the top, so they are put there
+	for i := range list {
+		if !done[i] {
+			top = append(top, nest(i))
+		}
+	}
+	return top
+}
+
+// Sets up the IDs of imported timers. The IDs they were exported with are kept if every timer
+// has a unique one, otherwise they are all given new IDs.
+func (s *TimerSet) importIDs() {
+	seen := make(map[int]bool)
+	if !s.uniqueIDs(seen) {
+		s.adopt(&idCounter{}, 0)
+		return
+	}
+	ids := &idCounter{}
+	for id := range seen {
+		if int64(id) > ids.last {
+			ids.last = int64(id)
+		}
+	}
+	s.mu.Lock()
+	if len(s.timers) > 0 {
+		s.parentId = s.timers[0].parentId
+	}
+	s.mu.Unlock()
+	s.useIDs(ids)
+}
+
+// Returns true if every timer in the tree, and every synthetic dropped timer, has an ID that
+// isn't in seen, adding them to it.
+func (s *TimerSet) uniqueIDs(seen map[int]bool) bool {
+	s.mu.Lock()
+	timers := append([]*Timer(nil), s.timers...)
+	droppedId := s.droppedId
+	s.mu.Unlock()
+	if droppedId != 0 {
+		if seen[droppedId] {
+			return false
+		}
+		seen[droppedId] = true
+	}
+	for _, t := range timers {
+		t.lock()
+		id, sub := t.id, t.subtimer
+		t.unlock()
+		if id == 0 || seen[id] {
+			return false
+		}
+		seen[id] = true
+		if sub != nil && !sub.uniqueIDs(seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// Shares the ID counter with the set, its timers and their children, without changing their IDs.
+func (s *TimerSet) useIDs(ids *idCounter) {
+	s.mu.Lock()
+	s.ids = ids
+	if s.dropped > 0 && s.droppedId == 0 {
+		s.droppedId = ids.next()
+	}
+	timers := append([]*Timer(nil), s.timers...)
+	s.mu.Unlock()
+	for _, t := range timers {
+		t.lock()
+		t.ids = ids
+		sub := t.subtimer
+		t.unlock()
+		if sub != nil {
+			sub.useIDs(ids)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock).SetLimits(Limits{MaxTimers: 4})
+	s.New("not started").Budget(time.Second)
+	s.New("instant").Start().Stop()
+	s.Mark("mark")
+	ctx, request := NewContextWithTimer(ctx, "request")
+	request.Start().Tag("tag").Attr("rows", 3).Add("bytes", 10)
+	running := From(ctx).New("running").Start()
+	paused := From(ctx).New("paused").Start()
+	clock.Advance(time.Millisecond)
+	running.Lap("lap").Event("event")
+	paused.Pause()
+	From(ctx).New("failed").Start().StopWithError(errBoom)
+	ctx = NewContext(ctx)
+	From(ctx).New("grouped").setLane(1)
+	clock.Advance(1500 * time.Microsecond)
+	request.Stop()
+	s.New("dropped 1")
+	s.New("dropped 2")
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported TimerSet
+	if err := json.Unmarshal(b, &imported); err != nil {
+		t.Fatal(err)
+	}
+	// So the JSON has the same time, as the running timers are still frozen at it
+	imported.SetClock(clock)
+	again, err := json.Marshal(&imported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(b) {
+		t.Errorf("Round trip changed the JSON:\n%s\n%s", b, again)
+	}
+
+	if timer := imported.FindPath("request/running"); !timer.IsRunning() || timer.Duration() != 2500*time.Microsecond ||
+		timer.ID() != running.ID() || timer.ParentID() != request.ID() {
+		t.Errorf("Running timer wasn't imported as it was: %s", timer.copy())
+	}
+	if timer := imported.FindPath("request/paused"); !timer.IsPaused() || timer.ActiveDuration() != time.Millisecond {
+		t.Errorf("Paused timer wasn't imported as it was: %s", timer.copy())
+	}
+	if timer := imported.Find("instant"); timer.IsRunning() || timer.Duration() != 0 {
+		t.Errorf("Timer with no duration was imported with %s", timer.Duration())
+	}
+	if timer := imported.Find("not started"); timer.IsRunning() || !timer.start.IsZero() {
+		t.Errorf("Timer that wasn't started was imported as %s", timer.copy())
+	}
+	if imported.Dropped() != 2 {
+		t.Errorf("Imported %d dropped timers", imported.Dropped())
+	}
+	// New timers carry on from the imported IDs, and use the TimerSet's clock
+	clock.Advance(time.Second)
+	if d := imported.FindPath("request/running").Duration(); d != 2500*time.Microsecond {
+		t.Errorf("Imported running timer wasn't frozen: %s", d)
+	}
+	if id := imported.New("new").ID(); id <= request.ID() {
+		t.Errorf("New timer has the ID %d", id)
+	}
+}
+
+func TestJSONTimerRoundTrip(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	timer := s.New("running").Start()
+	clock.Advance(time.Millisecond)
+	b, err := json.Marshal(timer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported Timer
+	if err := json.Unmarshal(b, &imported); err != nil {
+		t.Fatal(err)
+	}
+	if !imported.IsRunning() || imported.Duration() != time.Millisecond || imported.ID() != 1 {
+		t.Errorf("Timer was imported as %s with ID %d", imported.copy(), imported.ID())
+	}
+}
+
+func TestJSONTimerValue(t *testing.T) {
+	s := newSet().SetClock(NewManualClock(time.Unix(1644884400, 0)))
+	s.New("db").Tag("read").Start().Stop()
+	b, err := json.Marshal(s.All()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported map[string]interface{}
+	if err := json.Unmarshal(b, &exported); err != nil {
+		t.Fatal(err)
+	}
+	if exported["schema"] != float64(JSONSchema) || exported["name"] != "db" || exported["state"] != stateStopped ||
+		exported["id"] != float64(1) || exported["tags"] == nil {
+		t.Errorf("Timer value was exported as %s", b)
+	}
+	b, err = json.Marshal(struct{ T Timer }{s.All()[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"name":"db"`) {
+		t.Errorf("Timer value in a struct was exported as %s", b)
+	}
+}
+
+func TestJSONRebase(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	worker := newSet().SetClock(clock)
+	running := worker.New("running").Start()
+	paused := worker.New("paused").Start()
+	clock.Advance(time.Millisecond)
+	paused.Pause()
+	clock.Advance(time.Millisecond)
+	b, err := json.Marshal(worker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported TimerSet
+	if err := json.Unmarshal(b, &imported); err != nil {
+		t.Fatal(err)
+	}
+
+	s := newSet().SetClock(NewManualClock(time.Unix(1700000000, 0)))
+	parent := s.New("parent").Start()
+	parent.Attach(&imported, GraftOptions{Rebase: true})
+	if timer := s.FindPath("parent/running"); timer.Duration() != running.Duration() || !timer.start.Equal(parent.start) {
+		t.Errorf("Rebased running timer has duration %s, not %s", timer.Duration(), running.Duration())
+	}
+	if timer := s.FindPath("parent/paused"); timer.Duration() != paused.Duration() || timer.ActiveDuration() != time.Millisecond {
+		t.Errorf("Rebased paused timer has duration %s, not %s", timer.Duration(), paused.Duration())
+	}
+}
+
+func TestJSONFirstSchema(t *testing.T) {
+	data, err := os.ReadFile("examples/waterfall/fake-timings.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s TimerSet
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	timer := s.FindPath("GetInformationData/st-ca-1/beDelta.queryBy:application")
+	if timer == nil || timer.ID() != 7 || timer.ParentID() != 4 || timer.Milliseconds() != 519.714 {
+		t.Fatalf("Timer wasn't imported from the nested list: %v", timer)
+	}
+
+	flat := `[{"id":1,"parent":0,"name":"a","start":1644884400000,"duration":3},` +
+		`{"id":3,"parent":2,"name":"c","start":1644884400001,"duration":1},` +
+		`{"id":2,"parent":1,"name":"b","start":1644884400000,"duration":2},` +
+		`{"id":4,"parent":0,"name":"d","start":1644884400000,"duration":1},` +
+		`{"id":5,"parent":9,"name":"e","start":1644884400000,"duration":1}]`
+	var nested TimerSet
+	if err := json.Unmarshal([]byte(flat), &nested); err != nil {
+		t.Fatal(err)
+	}
+	var top []string
+	for _, timer := range nested.All() {
+		top = append(top, timer.name)
+	}
+	if !sameNames(top, "a", "d", "e") {
+		t.Errorf("Top level timers were %v", top)
+	}
+	if c := nested.FindPath("a/b/c"); c == nil || c.ID() != 3 || c.ParentID() != 2 {
+		t.Errorf("Flat list wasn't nested by parent: %s", &nested)
+	}
+}
+
+func TestJSONSchemaErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"schema":3,"time":0,"timers":[]}`,
+		`{"timers":[]}`,
+		`{"schema":2,"time":0,"timers":[{"id":1,"name":"a","state":"unknown"}]}`,
+	} {
+		var s TimerSet
+		if err := json.Unmarshal([]byte(data), &s); err == nil {
+			t.Errorf("No error unmarshalling %s", data)
+		}
+	}
+	var timer Timer
+	if err := json.Unmarshal([]byte(`{"schema":1,"name":"a"}`), &timer); err == nil {
+		t.Error("No error unmarshalling a timer with an unknown schema")
+	}
+}
@@ -0,0 +1,117 @@
+package timers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A Lap is a named checkpoint inside a single timer, like the lap button on a stopwatch. They
+// are used to measure the phases of one operation (connect, send, wait, read) without creating
+// a timer for each phase.
+type Lap struct {
+	Name     string
+	Split    time.Duration // Time from the start of the timer to the end of this lap
+	Duration time.Duration // Time from the end of the previous lap (or the start) to this lap
+}
+
+// Records a named checkpoint. The lap covers the time since the previous lap, or since the
+// timer was started if this is the first lap. Laps are only recorded while the timer is
+// running, otherwise this does nothing.
+//  t := timers.From(ctx).New("fetch").Start()
+//  conn := connect()
+//  t.Lap("connect")
+//  send(conn)
+//  t.Lap("send")
+//  read(conn)
+//  t.Lap("read").Stop()
+func (t *Timer) Lap(name string) *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
+	if !t.running() {
+		return t
+	}
+	// Read under the lock, so laps recorded by different go routines are in order
+	split := t.now().Sub(t.start)
+	var prev time.Duration
+	if n := len(t.laps); n > 0 {
+		prev = t.laps[n-1].Split
+	}
+	t.laps = append(t.laps, Lap{Name: name, Split: split, Duration: split - prev})
+	return t
+}
+
+// Returns a copy of the laps recorded on the timer, in order.
+func (t *Timer) Laps() []Lap {
+	t.lock()
+	defer t.unlock()
+	laps := make([]Lap, len(t.laps))
+	copy(laps, t.laps)
+	return laps
+}
+
+// Formats a list of laps for Timer.String()
+func fmtLaps(laps []Lap) string {
+	str := make([]string, len(laps))
+	for i, l := range laps {
+		str[i] = fmt.Sprintf("%s=%.3fms", l.Name, durationMs(l.Duration))
+	}
+	return strings.Join(str, ",")
+}
+
+// Formats a list of laps for the Server-Timing header, as a query string of lap name to lap
+// duration in milliseconds, eg: connect=1.200&send=0.420
+func fmtLapsHeader(laps []Lap) string {
+	str := make([]string, len(laps))
+	for i, l := range laps {
+		str[i] = fmt.Sprintf("%s=%.3f", url.QueryEscape(l.Name), durationMs(l.Duration))
+	}
+	return strings.Join(str, "&")
+}
+
+// Returns the duration in milliseconds rounded to 3 decimal places, like Timer.Milliseconds()
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / float64(1000)
+}
+
+// Marshaling type of the first JSON schema, in milliseconds
+type marshalLap struct {
+	Name     string  `json:"name"`
+	Split    float64 `json:"split"`
+	Duration float64 `json:"duration"`
+}
+
+func (ml marshalLap) toLap() Lap {
+	return Lap{
+		Name:     ml.Name,
+		Split:    time.Duration(ml.Split * float64(time.Millisecond)),
+		Duration: time.Duration(ml.Duration * float64(time.Millisecond)),
+	}
+}
+
+// Marshaling type of the current JSON schema, in nanoseconds
+type marshalLapV2 struct {
+	Name     string `json:"name"`
+	Split    int64  `json:"split"`
+	Duration int64  `json:"duration"`
+}
+
+func (l Lap) toMarshalLapV2() marshalLapV2 {
+	return marshalLapV2{
+		Name:     l.Name,
+		Split:    int64(l.Split),
+		Duration: int64(l.Duration),
+	}
+}
+
+func (ml marshalLapV2) toLap() Lap {
+	return Lap{
+		Name:     ml.Name,
+		Split:    time.Duration(ml.Split),
+		Duration: time.Duration(ml.Duration),
+	}
+}
@@ -0,0 +1,71 @@
+package timers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLap(t *testing.T) {
+	timer := newSet().New("laps")
+	timer.Lap("not started")
+	if len(timer.Laps()) != 0 {
+		t.Error("Lap recorded on a timer that hasn't started")
+	}
+	timer.Start().nap().Lap("connect").nap().Lap("send").Stop()
+	timer.Lap("stopped")
+	laps := timer.Laps()
+	if len(laps) != 2 {
+		t.Fatalf("Expected 2 laps, got %d", len(laps))
+	}
+	if laps[0].Name != "connect" || laps[1].Name != "send" {
+		t.Errorf("Laps in the wrong order: %v", laps)
+	}
+	if laps[0].Split != laps[0].Duration {
+		t.Error("First lap's duration isn't the same as its split")
+	}
+	if laps[1].Split != laps[0].Split+laps[1].Duration {
+		t.Error("Second lap's split isn't the sum of the lap durations")
+	}
+	if laps[1].Split > timer.Duration() {
+		t.Error("Lap ended after the timer was stopped")
+	}
+}
+
+func TestLapExport(t *testing.T) {
+	s := newSet()
+	timer := s.New("laps").Start()
+	timer.Lap("first lap").Lap("a&b").Stop()
+	// Make the laps predictable
+	timer.laps[0].Split, timer.laps[0].Duration = 1500*time.Microsecond, 1500*time.Microsecond
+	timer.laps[1].Split, timer.laps[1].Duration = 2000*time.Microsecond, 500*time.Microsecond
+
+	if str := timer.copy().String(); !strings.Contains(str, "laps:(first lap=1.500ms,a&b=0.500ms)") {
+		t.Errorf("String() did not contain laps: %s", str)
+	}
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.Contains(header, `;laps="first+lap=1.500&a%26b=0.500"`) {
+		t.Errorf("Header did not contain laps: %s", header)
+	}
+
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bytes), `"laps":[{"name":"first lap","split":1500000,"duration":1500000}`) {
+		t.Errorf("JSON did not contain laps: %s", bytes)
+	}
+	var s2 TimerSet
+	if err := json.Unmarshal(bytes, &s2); err != nil {
+		t.Fatal(err)
+	}
+	laps := s2.Find("laps").Laps()
+	if len(laps) != 2 || laps[0] != timer.laps[0] || laps[1] != timer.laps[1] {
+		t.Errorf("Laps did not survive a round trip: %v", laps)
+	}
+}
@@ -0,0 +1,110 @@
+package timers
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+)
+
+// LeakKind is the way a timer was leaked, see TimerSet.Leaks.
+type LeakKind string
+
+const (
+	LeakNotStarted LeakKind = "not started" // The timer was created but never started
+	LeakNotStopped LeakKind = "not stopped" // The timer is still running, or was only stopped by StopAllTimers
+)
+
+// A Leak is a timer that was created but never started, or started but never stopped.
+type Leak struct {
+	Kind   LeakKind
+	Path   string // Names of the timer's parents and the timer, separated by '/', see FindPath
+	Origin string // Where the timer was created (file:line), if the TimerSet was in debug mode
+	Timer  Timer  // A copy of the timer
+}
+
+// Leaks are the result of TimerSet.Leaks, in the order of the tree.
+type Leaks []Leak
+
+// Turns debug mode on or off for timers created in this TimerSet after this call, and in
+// TimerSets created from it with NewContext, NewContextWithTimer and Wrap. In debug mode each
+// timer records where it was created, which is reported by Leaks() and Timer.Origin(). This
+// costs a call to runtime.Caller for each timer, so it is off by default.
+func (s *TimerSet) SetDebug(debug bool) *TimerSet {
+	if s.readOnly() {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.debug = debug
+	return s
+}
+
+// Returns where the timer was created, as file:line, or an empty string if it was not created
+// in debug mode (see TimerSet.SetDebug).
+func (t *Timer) Origin() string {
+	t.lock()
+	defer t.unlock()
+	return t.origin
+}
+
+// Returns the timers in the tree that were created but never started, and those that were
+// started but never stopped: ones that are still running, or were only stopped by
+// StopAllTimers (which the middleware calls before adding the Server-Timing header). With debug
+// mode on (see SetDebug) each leak includes where the timer was created. This is intended for
+// tests and the middleware Callback:
+//  timers.Middleware(handler, timers.MiddlewareOptions{
+//      Debug: true,
+//      Callback: func(s *timers.TimerSet) {
+//          if leaks := s.Leaks(); len(leaks) > 0 {
+//              log.Printf("Leaked timers:\n%s", leaks)
+//          }
+//      },
+//  })
+func (s *TimerSet) Leaks() Leaks {
+	var leaks Leaks
+	s.findLeaks("", &leaks)
+	return leaks
+}
+
+func (s *TimerSet) findLeaks(prefix string, leaks *Leaks) {
+	for _, t := range s.All() {
+		path := prefix + t.name
+		var kind LeakKind
+		if t.start.IsZero() && !t.group {
+			kind = LeakNotStarted
+		} else if t.running() || t.stoppedByAll {
+			kind = LeakNotStopped
+		}
+		if kind != "" {
+			*leaks = append(*leaks, Leak{Kind: kind, Path: path, Origin: t.origin, Timer: t})
+		}
+		if t.subtimer != nil {
+			t.subtimer.findLeaks(path+"/", leaks)
+		}
+	}
+}
+
+// Renders the leaks as a text table.
+func (leaks Leaks) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, l := range leaks {
+		origin := l.Origin
+		if origin == "" {
+			origin = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", l.Kind, l.Path, origin)
+	}
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Returns the file:line of the caller skip frames up, like runtime.Caller.
+func callerOrigin(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
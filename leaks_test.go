@@ -0,0 +1,97 @@
+package timers
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Returns the file:line of the line after the call.
+func nextLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("%s:%d", file, line+1)
+}
+
+func TestLeaks(t *testing.T) {
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetDebug(true)
+	s.New("stopped").Start().Stop()
+	s.Mark("mark")
+	origin := nextLine()
+	s.New("not started")
+	running := nextLine()
+	s.New("running").Start()
+	ctx = NewContext(ctx)
+	ctx, withTimer := NewContextWithTimer(ctx, "with timer")
+	withTimer.Start()
+	forgotten := nextLine()
+	From(ctx).New("forgotten").Start()
+	withTimer.Stop()
+	s.StopAllTimers()
+
+	leaks := s.Leaks()
+	if len(leaks) != 3 {
+		t.Fatalf("Found %d leaks, not 3:\n%s", len(leaks), leaks)
+	}
+	expect := []Leak{
+		{Kind: LeakNotStarted, Path: "not started", Origin: origin},
+		{Kind: LeakNotStopped, Path: "running", Origin: running},
+		{Kind: LeakNotStopped, Path: "Subtimer/with timer/forgotten", Origin: forgotten},
+	}
+	for i, e := range expect {
+		l := leaks[i]
+		if l.Kind != e.Kind || l.Path != e.Path || l.Origin != e.Origin {
+			t.Errorf("Leak %d was %s %s %s, expected %s %s %s", i, l.Kind, l.Path, l.Origin, e.Kind, e.Path, e.Origin)
+		}
+	}
+	if leaks[1].Timer.name != "running" || leaks[1].Timer.IsRunning() {
+		t.Errorf("Leak has the wrong timer: %s", leaks[1].Timer)
+	}
+	str := leaks.String()
+	if !strings.HasPrefix(str, "not started  not started") || !strings.HasSuffix(str, forgotten) {
+		t.Errorf("Leaks were\n%s", str)
+	}
+}
+
+func TestLeaksWithoutDebug(t *testing.T) {
+	s := newSet()
+	s.New("running").Start()
+	leaks := s.Leaks()
+	if len(leaks) != 1 || leaks[0].Kind != LeakNotStopped || leaks[0].Origin != "" {
+		t.Fatalf("Leaks were %v", leaks)
+	}
+	if leaks.String() != "not stopped  running  -" {
+		t.Errorf("Leaks were %q", leaks.String())
+	}
+	if s.Find("running").Origin() != "" {
+		t.Error("Timer recorded its origin without debug mode")
+	}
+	s.Find("running").Stop()
+	if leaks := s.Leaks(); len(leaks) != 0 {
+		t.Errorf("Stopped timer was reported as a leak: %v", leaks)
+	}
+}
+
+func TestDebugOrigins(t *testing.T) {
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetDebug(true)
+	origin := nextLine()
+	s.Wrap(ctx, "wrapped", func(ctx context.Context) {
+		if From(ctx).New("child").Origin() == "" {
+			t.Error("Child TimerSet didn't inherit debug mode")
+		}
+	})
+	if o := s.Find("wrapped").Origin(); o != origin {
+		t.Errorf("Wrapped timer's origin was %s, not %s", o, origin)
+	}
+	origin = nextLine()
+	_, timer := NewContextWithTimer(ctx, "with timer")
+	if timer.Origin() != origin {
+		t.Errorf("Timer's origin was %s, not %s", timer.Origin(), origin)
+	}
+	if s.SetDebug(false).New("after").Origin() != "" {
+		t.Error("Timer recorded its origin after debug mode was turned off")
+	}
+}
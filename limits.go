@@ -0,0 +1,126 @@
+package timers
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Limits bound the number of timers a TimerSet tree will hold. Timers created beyond a limit
+// are dropped: they still work, but are not added to the TimerSet, and are instead counted. The
+// count is reported by String(), MarshalJSON() and AddHeader() as a "N timers dropped" entry.
+// A limit of zero means no limit.
+type Limits struct {
+	MaxTimers int // Maximum number of timers in each TimerSet
+	MaxDepth  int // Maximum depth of child TimerSets, counted from the TimerSet the limits are set on
+	MaxTotal  int // Maximum number of timers in the whole tree
+}
+
+// Counts the timers across a tree of TimerSets, for Limits.MaxTotal
+type timerCount struct {
+	n int64
+}
+
+// Sets the limits for this TimerSet, which are inherited by TimerSets created from it with
+// NewContext, NewContextWithTimer and Wrap. Limits only apply to timers and TimerSets created
+// after they are set.
+func (s *TimerSet) SetLimits(limits Limits) *TimerSet {
+	if s.readOnly() {
+		return s
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+	s.depth = 0
+	s.tree = nil
+	if limits.MaxTotal > 0 {
+		s.tree = &timerCount{n: int64(len(s.timers))}
+	}
+	return s
+}
+
+// Returns the limits of this TimerSet.
+func (s *TimerSet) Limits() Limits {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limits
+}
+
+// Returns the number of timers that were dropped from this TimerSet because of its limits.
+// This includes timers dropped from child TimerSets that could not be attached to the tree.
+func (s *TimerSet) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Reserves room for another timer in the set. Caller must hold the lock.
+func (s *TimerSet) reserve() bool {
+	if s.limits.MaxTimers > 0 && len(s.timers) >= s.limits.MaxTimers {
+		return false
+	}
+	if s.tree != nil {
+		if atomic.AddInt64(&s.tree.n, 1) > int64(s.limits.MaxTotal) {
+			atomic.AddInt64(&s.tree.n, -1)
+			return false
+		}
+	}
+	return true
+}
+
+// Counts a dropped timer.
+func (s *TimerSet) addDropped(n int) {
+	s.mu.Lock()
+	s.countDropped(n)
+	s.mu.Unlock()
+}
+
+// Counts dropped timers, giving the synthetic dropped timer an ID the first time. Caller must
+// hold the lock.
+func (s *TimerSet) countDropped(n int) {
+	s.dropped += n
+	if s.dropped > 0 && s.droppedId == 0 {
+		s.droppedId = s.idCounter().next()
+	}
+}
+
+// Marks a child TimerSet as detached from the tree. All timers created in it are dropped, and
+// counted against the target.
+func (s *TimerSet) detach(target *TimerSet) {
+	s.mu.Lock()
+	s.dropTo = target
+	s.mu.Unlock()
+}
+
+// Returns a synthetic timer reporting how many timers were dropped from this set, or nil if
+// none were.
+func (s *TimerSet) droppedTimer() *Timer {
+	s.mu.Lock()
+	n, id, parentId := s.dropped, s.droppedId, s.parentId
+	s.mu.Unlock()
+	if n == 0 {
+		return nil
+	}
+	return &Timer{
+		mu:       &sync.Mutex{},
+		name:     fmt.Sprintf("%d timers dropped", n),
+		dropped:  n,
+		id:       id,
+		parentId: parentId,
+	}
+}
+
+// Removes any synthetic dropped timers from an imported set, restoring the dropped count and
+// the synthetic timer's ID. Caller must hold the lock.
+func (s *TimerSet) collectDropped() {
+	timers := s.timers[:0]
+	for _, t := range s.timers {
+		if t.dropped > 0 {
+			s.dropped += t.dropped
+			s.droppedId = t.id
+		} else {
+			timers = append(timers, t)
+		}
+	}
+	s.timers = timers
+}
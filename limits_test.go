@@ -0,0 +1,126 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxTimers(t *testing.T) {
+	s := newSet().SetLimits(Limits{MaxTimers: 3})
+	for i := 0; i < 10; i++ {
+		timer := s.New("timer %d", i).Start().Stop()
+		if timer.IsRunning() || timer.Duration() == 0 && i >= 3 {
+			t.Error("Dropped timer doesn't work")
+		}
+	}
+	if len(s.All()) != 3 {
+		t.Errorf("Expected 3 timers, got %d", len(s.All()))
+	}
+	if s.Dropped() != 7 {
+		t.Errorf("Expected 7 dropped timers, got %d", s.Dropped())
+	}
+	if s.Find("timer 3") != nil {
+		t.Error("Found a dropped timer")
+	}
+	if !strings.HasSuffix(s.String(), "\n7 timers dropped") {
+		t.Errorf("String() didn't report dropped timers: %s", s.String())
+	}
+	if len(s.AllDeep()) != 3 {
+		t.Error("AllDeep() included the dropped timer report")
+	}
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.HasSuffix(header, `, 7_timers_dropped;descr="7 timers dropped";dur=0;parent=0;id=4;dropped=7`) {
+		t.Errorf("Header didn't report dropped timers: %s", header)
+	}
+
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bytes), `{"id":4,"parent":0,"name":"7 timers dropped","state":"notStarted","duration":0,"dropped":7}`) {
+		t.Errorf("JSON didn't report dropped timers: %s", bytes)
+	}
+	var s2 TimerSet
+	if err := json.Unmarshal(bytes, &s2); err != nil {
+		t.Fatal(err)
+	}
+	if len(s2.All()) != 3 || s2.Dropped() != 7 {
+		t.Errorf("Dropped timers didn't survive a round trip: %d timers, %d dropped", len(s2.All()), s2.Dropped())
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	ctx := NewContext(context.Background())
+	From(ctx).SetLimits(Limits{MaxDepth: 1})
+	depth1, _ := NewContextWithTimer(ctx, "depth1")
+	From(depth1).New("kept")
+	depth2, t2 := NewContextWithTimer(depth1, "depth2")
+	From(depth2).New("dropped")
+	From(depth2).Wrap(depth2, "wrap", func(depth3 context.Context) {
+		From(depth3).New("dropped").Start().Stop()
+	})
+	t2.Start().Stop()
+
+	if len(From(ctx).AllDeep()) != 3 {
+		t.Errorf("Expected 3 timers, got %d", len(From(ctx).AllDeep()))
+	}
+	if From(depth1).Dropped() != 3 {
+		t.Errorf("Expected 3 timers dropped in the deepest set, got %d", From(depth1).Dropped())
+	}
+	if !strings.Contains(From(ctx).String(), "3 timers dropped") {
+		t.Errorf("String() didn't report dropped timers: %s", From(ctx).String())
+	}
+
+	bytes, err := json.Marshal(From(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s2 TimerSet
+	if err := json.Unmarshal(bytes, &s2); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Find("depth1").sub().Dropped() != 3 {
+		t.Error("Child set's dropped timers didn't survive a round trip")
+	}
+}
+
+func TestMaxTotal(t *testing.T) {
+	ctx := NewContext(context.Background())
+	From(ctx).New("before limits")
+	From(ctx).SetLimits(Limits{MaxTotal: 4})
+	From(ctx).New("root")
+	child, _ := NewContextWithTimer(ctx, "child")
+	for i := 0; i < 5; i++ {
+		From(child).New("child %d", i)
+	}
+	if len(From(ctx).AllDeep()) != 4 {
+		t.Errorf("Expected 4 timers in the tree, got %d", len(From(ctx).AllDeep()))
+	}
+	if From(child).Dropped() != 4 {
+		t.Errorf("Expected 4 dropped in the child, got %d", From(child).Dropped())
+	}
+	if From(ctx).Limits().MaxTotal != 4 {
+		t.Error("Limits() didn't return the limits")
+	}
+}
+
+func TestDroppedSubtimer(t *testing.T) {
+	s := newSet().SetLimits(Limits{MaxTimers: 1})
+	s.New("only")
+	ctx := context.WithValue(context.Background(), timerctx("timers"), s)
+	child, timer := NewContextWithTimer(ctx, "dropped")
+	From(child).New("lost")
+	From(child).New("lost")
+	if timer.sub() == nil {
+		t.Error("Dropped timer lost its subtimer")
+	}
+	if s.Dropped() != 3 {
+		t.Errorf("Expected the dropped timer and its children to be counted, got %d", s.Dropped())
+	}
+}
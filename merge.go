@@ -0,0 +1,198 @@
+package timers
+
+import "time"
+
+// GraftOptions control how Attach and Merge graft timers into a tree.
+type GraftOptions struct {
+	// Shifts the start times of the grafted timers (keeping their durations and the gaps
+	// between them), so the earliest starts with the timer they are attached to, or with the
+	// earliest timer in the TimerSet they are merged into. Use this when the timers were
+	// recorded elsewhere, such as a worker process with a different clock, so that they line
+	// up in the waterfall.
+	Rebase bool
+}
+
+// Grafts a TimerSet, such as one from UnmarshalJSON or produced by a worker, under this timer,
+// as if it had been created with NewContextWithTimer. If the timer already has children, the
+// timers are merged into them (see Merge), otherwise the TimerSet itself becomes the timer's
+// children.
+//
+// Attaching a TimerSet that the timer is in, directly or under one of its timers, would make
+// the tree a loop, so it panics. A TimerSet can only be under one timer, so attaching one that
+// is already under a timer (such as one from NewContext, or one that was attached before) also
+// panics.
+//  var worker timers.TimerSet
+//  json.Unmarshal(response, &worker)
+//  timer.Attach(&worker, timers.GraftOptions{Rebase: true})
+func (t *Timer) Attach(s *TimerSet, opts GraftOptions) *Timer {
+	if t.readOnly() || s == nil || s.readOnly() {
+		return t
+	}
+	if s.contains(nil, t) {
+		panic("timers: can't attach a TimerSet to a timer in it")
+	}
+	s.mu.Lock()
+	attached := s.attached
+	s.mu.Unlock()
+	if attached {
+		panic("timers: can't attach a TimerSet that is already under a timer")
+	}
+	t.lock()
+	start := t.start
+	t.unlock()
+	if opts.Rebase && !start.IsZero() {
+		s.mu.Lock()
+		timers := append([]*Timer(nil), s.timers...)
+		s.mu.Unlock()
+		rebase(timers, start)
+	}
+	t.lock()
+	sub := t.subtimer
+	if sub == nil {
+		s.mu.Lock()
+		attached := s.attached
+		s.attached = true
+		s.mu.Unlock()
+		if attached {
+			// Attached to another timer since it was checked above
+			t.unlock()
+			panic("timers: can't attach a TimerSet that is already under a timer")
+		}
+		t.subtimer = s
+		if t.ids == nil {
+			t.ids = &idCounter{}
+		}
+	}
+	ids, id := t.ids, t.id
+	t.unlock()
+	if sub != nil {
+		sub.Merge(s, GraftOptions{})
+	} else {
+		s.adopt(ids, id)
+	}
+	return t
+}
+
+// Moves all the timers from other into this TimerSet, after the timers already in it. Timers
+// created in other after the merge are not moved. The limits of this TimerSet apply to the
+// moved timers (but not their children), and timers dropped from other are counted as dropped
+// from this TimerSet. Merging a TimerSet into itself does nothing, and merging one that this
+// TimerSet is under would make the tree a loop, so it panics.
+func (s *TimerSet) Merge(other *TimerSet, opts GraftOptions) *TimerSet {
+	if s.readOnly() || other == nil || other.readOnly() || other == s {
+		return s
+	}
+	if other.contains(s, nil) {
+		panic("timers: can't merge a TimerSet into one of its children")
+	}
+	other.mu.Lock()
+	timers, dropped := other.timers, other.dropped
+	other.timers, other.dropped = nil, 0
+	other.mu.Unlock()
+
+	if opts.Rebase {
+		var start time.Time
+		for _, t := range s.All() {
+			if !t.start.IsZero() && (start.IsZero() || t.start.Before(start)) {
+				start = t.start
+			}
+		}
+		if !start.IsZero() {
+			rebase(timers, start)
+		}
+	}
+
+	s.mu.Lock()
+	ids, parentId := s.idCounter(), s.parentId
+	s.mu.Unlock()
+	adoptTimers(timers, ids, parentId)
+
+	s.mu.Lock()
+	var target *TimerSet
+	n := 0
+	for _, t := range timers {
+		if tt := s.insert(t); tt != nil {
+			target = tt
+			n++
+		}
+	}
+	s.countDropped(dropped)
+	s.mu.Unlock()
+	if target != nil {
+		target.addDropped(n)
+	}
+	return s
+}
+
+// Returns true if set or t is this TimerSet, one of its timers, or anywhere under them. These are
+// the original timers, not copies.
+func (s *TimerSet) contains(set *TimerSet, t *Timer) bool {
+	if s == set {
+		return true
+	}
+	s.mu.Lock()
+	timers := append([]*Timer(nil), s.timers...)
+	s.mu.Unlock()
+	for _, child := range timers {
+		if child == t {
+			return true
+		}
+		if sub := child.sub(); sub != nil && sub.contains(set, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Shifts the start times of the timers and all their children, so the earliest starts at start.
+func rebase(timers []*Timer, start time.Time) {
+	all := deepTimers(timers)
+	var earliest time.Time
+	for _, t := range all {
+		t.lock()
+		if !t.start.IsZero() && (earliest.IsZero() || t.start.Before(earliest)) {
+			earliest = t.start
+		}
+		t.unlock()
+	}
+	if earliest.IsZero() {
+		return
+	}
+	shift := start.Sub(earliest)
+	// Imported running timers are frozen at the time they were exported, which moves with them
+	frozen := make(map[*frozenClock]*frozenClock)
+	for _, t := range all {
+		t.lock()
+		if !t.start.IsZero() {
+			t.start = t.start.Add(shift)
+		}
+		if c, ok := t.clock.(*frozenClock); ok {
+			if frozen[c] == nil {
+				frozen[c] = &frozenClock{now: c.now.Add(shift)}
+			}
+			t.clock = frozen[c]
+		}
+		if !t.resumed.IsZero() {
+			t.resumed = t.resumed.Add(shift)
+		}
+		for i := range t.events {
+			t.events[i].Time = t.events[i].Time.Add(shift)
+		}
+		t.unlock()
+	}
+}
+
+// Returns the timers and all of their children. These are the original timers, not copies.
+func deepTimers(timers []*Timer) []*Timer {
+	var all []*Timer
+	for _, t := range timers {
+		all = append(all, t)
+		if sub := t.sub(); sub != nil {
+			sub.mu.Lock()
+			children := append([]*Timer(nil), sub.timers...)
+			sub.mu.Unlock()
+			all = append(all, deepTimers(children)...)
+		}
+	}
+	return all
+}
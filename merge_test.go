@@ -0,0 +1,190 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// A capture from a worker: "work" starts at 1s and runs for 30ms, with "step" starting 10ms
+// after it.
+var workerJSON = `[{"name":"work","start":1000,"duration":30,"children":[{"name":"step","start":1010,"duration":5}]}]`
+
+func workerSet(t *testing.T) *TimerSet {
+	var s TimerSet
+	if err := json.Unmarshal([]byte(workerJSON), &s); err != nil {
+		t.Fatal(err)
+	}
+	return &s
+}
+
+func TestAttach(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	parent := s.New("parent").Start()
+	parent.Attach(workerSet(t), GraftOptions{})
+
+	all := s.AllDeep()
+	if !sameNames(names(all), "parent", "work", "step") {
+		t.Fatalf("Tree after attaching was %v", names(all))
+	}
+	if all[1].parentId != all[0].id || all[2].parentId != all[1].id {
+		t.Error("Attached timers are not children of the timer")
+	}
+	if !all[1].start.Equal(time.UnixMilli(1000)) {
+		t.Errorf("Attached timer was moved to %s without rebasing", all[1].start)
+	}
+	if len(s.FindAll("step")) != 1 {
+		t.Error("Attached timers aren't found in the tree")
+	}
+}
+
+func TestAttachRebase(t *testing.T) {
+	start := time.Unix(1644884400, 0)
+	s := newSet().SetClock(NewManualClock(start))
+	parent := s.New("parent").Start()
+	parent.Attach(workerSet(t), GraftOptions{Rebase: true})
+
+	work := s.FindPath("parent/work")
+	step := s.FindPath("parent/work/step")
+	if !work.start.Equal(start) {
+		t.Errorf("Rebased timer starts at %s, not with its parent at %s", work.start, start)
+	}
+	if step.start.Sub(work.start) != 10*time.Millisecond {
+		t.Errorf("Rebasing changed the gap between timers to %s", step.start.Sub(work.start))
+	}
+	if work.Duration() != 30*time.Millisecond || step.Duration() != 5*time.Millisecond {
+		t.Error("Rebasing changed the durations")
+	}
+
+	// A timer that hasn't started has nothing to line up with
+	notStarted := s.New("not started")
+	notStarted.Attach(workerSet(t), GraftOptions{Rebase: true})
+	if !s.FindPath("not started/work").start.Equal(time.UnixMilli(1000)) {
+		t.Error("Timers attached to a timer that hasn't started were rebased")
+	}
+}
+
+func TestAttachExistingChildren(t *testing.T) {
+	s := newSet()
+	parent := s.New("parent")
+	sub := s.newChild()
+	sub.New("existing")
+	parent.subtimer = sub
+	worker := workerSet(t)
+	parent.Attach(worker, GraftOptions{})
+	if !sameNames(names(sub.AllDeep()), "existing", "work", "step") {
+		t.Errorf("Attaching to a timer with children gave %v", names(sub.AllDeep()))
+	}
+	if len(worker.All()) != 0 {
+		t.Error("Merged timers were left in the attached TimerSet")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	start := time.Unix(1644884400, 0)
+	s := newSet().SetClock(NewManualClock(start))
+	s.New("first").Start().Stop()
+	worker := workerSet(t)
+	worker.addDropped(2)
+	s.Merge(worker, GraftOptions{Rebase: true})
+
+	if !sameNames(names(s.AllDeep()), "first", "work", "step") {
+		t.Errorf("Merged tree was %v", names(s.AllDeep()))
+	}
+	if len(worker.All()) != 0 || worker.Dropped() != 0 {
+		t.Error("Merged timers were left in the other TimerSet")
+	}
+	if s.Dropped() != 2 {
+		t.Errorf("Merged TimerSet has %d dropped timers, not 2", s.Dropped())
+	}
+	if !s.Find("work").start.Equal(start) {
+		t.Errorf("Merged timer was rebased to %s, not %s", s.Find("work").start, start)
+	}
+
+	// Merging into itself or nil does nothing
+	s.Merge(s, GraftOptions{}).Merge(nil, GraftOptions{})
+	if len(s.All()) != 2 {
+		t.Error("Merging a TimerSet into itself changed it")
+	}
+}
+
+func TestGraftLoop(t *testing.T) {
+	ctx := NewContext(context.Background())
+	s := From(ctx)
+	s.New("x")
+	ctx, parent := NewContextWithTimer(ctx, "parent")
+	child := From(ctx).New("child")
+	mustPanic := func(what string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s didn't panic", what)
+			}
+		}()
+		fn()
+	}
+	mustPanic("Attaching a TimerSet to its own timer", func() {
+		s.Find("x").Attach(s, GraftOptions{})
+	})
+	mustPanic("Attaching a TimerSet under its own timer", func() {
+		child.Attach(s, GraftOptions{})
+	})
+	mustPanic("Merging a TimerSet into its child", func() {
+		From(ctx).Merge(s, GraftOptions{})
+	})
+	mustPanic("Attaching a TimerSet to a timer with it as a child", func() {
+		parent.Attach(s, GraftOptions{})
+	})
+	if !sameNames(names(s.AllDeep()), "x", "parent", "child") {
+		t.Errorf("Tree was changed to %v", names(s.AllDeep()))
+	}
+}
+
+func TestAttachTwice(t *testing.T) {
+	ctx := NewContext(context.Background())
+	s := From(ctx)
+	a := s.New("a")
+	b := s.New("b")
+	cctx, _ := NewContextWithTimer(ctx, "c")
+	worker := workerSet(t)
+	a.Attach(worker, GraftOptions{})
+	for what, set := range map[string]*TimerSet{"an attached TimerSet": worker, "a child TimerSet": From(cctx)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Attaching %s to a second timer didn't panic", what)
+				}
+			}()
+			b.Attach(set, GraftOptions{})
+		}()
+	}
+
+	done := make(chan string)
+	go func() { done <- s.String() }()
+	select {
+	case str := <-done:
+		if str != "a: NotStarted\nb: NotStarted\nc: NotStarted\nwork: 30.000ms\nstep: 5.000ms" {
+			t.Errorf("Tree was changed to\n%s", str)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("String() hung on a TimerSet under two timers")
+	}
+}
+
+func TestMergeLimits(t *testing.T) {
+	s := newSet().SetLimits(Limits{MaxTimers: 2})
+	s.New("first")
+	other := newSet()
+	other.New("a")
+	other.New("b")
+	other.New("c")
+	s.Merge(other, GraftOptions{})
+	if !sameNames(names(s.AllDeep()), "first", "a") {
+		t.Errorf("Merged tree was %v", names(s.AllDeep()))
+	}
+	if s.Dropped() != 2 {
+		t.Errorf("Merge dropped %d timers, not 2", s.Dropped())
+	}
+}
@@ -2,14 +2,22 @@ package timers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/felixge/httpsnoop"
 )
 
 type MiddlewareOptions struct {
-	Callback       func(*TimerSet) // This function will be called at the end of the request
-	NoDefaultTimer bool            // If true, then no default timer will be set.
-	StopAllTimers  bool            // Stop all timers before adding them to the Server-Timing header
+	Callback       func(*TimerSet)          // This function will be called at the end of the request
+	NoDefaultTimer bool                     // If true, then no default timer will be set.
+	StopAllTimers  bool                     // Stop all timers before adding them to the Server-Timing header
+	Clock          Clock                    // If set, the Clock used by the request's timers
+	Limits         Limits                   // Limits on the number of timers each request may create
+	Budgets        map[string]time.Duration // Budgets by timer name, see TimerSet.SetBudget
+	OnOverBudget   func(Timer)              // Called when a timer is over budget, see TimerSet.OnOverBudget
+	Watchdog       WatchdogOptions          // If the Threshold is set, a watchdog runs for each request, see TimerSet.Watchdog
+	Debug          bool                     // Record where each timer is created, see TimerSet.SetDebug and Leaks
+	StopOnCancel   bool                     // Stop running timers if the request is cancelled, see TimerSet.StopOnDone
 }
 
 // The middleware function sets up timers for each request, and for each request emits
@@ -19,6 +27,28 @@ type MiddlewareOptions struct {
 func Middleware(next http.Handler, opts MiddlewareOptions) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := NewContext(r.Context())
+		if opts.Clock != nil {
+			From(ctx).SetClock(opts.Clock)
+		}
+		if opts.Debug {
+			From(ctx).SetDebug(true)
+		}
+		if opts.StopOnCancel {
+			From(ctx).StopOnDone(ctx)
+		}
+		if opts.Limits != (Limits{}) {
+			From(ctx).SetLimits(opts.Limits)
+		}
+		for name, d := range opts.Budgets {
+			From(ctx).SetBudget(name, d)
+		}
+		if opts.OnOverBudget != nil {
+			From(ctx).OnOverBudget(opts.OnOverBudget)
+		}
+		if opts.Watchdog.Threshold > 0 {
+			stop := From(ctx).Watchdog(opts.Watchdog)
+			defer stop()
+		}
 		r = r.WithContext(ctx)
 		var t *Timer
 
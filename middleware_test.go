@@ -1,11 +1,13 @@
 package timers_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/zafnz/go-timers"
 )
@@ -98,3 +100,164 @@ func TestMiddlewareWriteHeader(t *testing.T) {
 		t.Error("Server-Timing does not contain test timer")
 	}
 }
+
+func TestMiddlewareClock(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := timers.NewManualClock(time.Unix(1644884400, 0))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timer := timers.From(r.Context()).New("test").Start()
+		clock.Advance(2 * time.Millisecond)
+		timer.Stop()
+		clock.Advance(time.Millisecond)
+	})
+
+	middleware := timers.Middleware(handler, timers.MiddlewareOptions{Clock: clock})
+	middleware.ServeHTTP(rr, req)
+	expect := `Request;descr="Request";dur=3.000;start=1644884400000;parent=0;id=1, ` +
+		`test;descr="test";dur=2.000;start=1644884400000;parent=0;id=2`
+	if timingHeader := rr.Header().Get("Server-Timing"); timingHeader != expect {
+		t.Errorf("Server-Timing was\n%s\nexpected\n%s", timingHeader, expect)
+	}
+}
+
+func TestMiddlewareLimits(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 50000; i++ {
+			timers.From(r.Context()).New("item %d", i).Start().Stop()
+		}
+	})
+
+	middleware := timers.Middleware(handler, timers.MiddlewareOptions{Limits: timers.Limits{MaxTimers: 100}})
+	middleware.ServeHTTP(rr, req)
+	timingHeader := rr.Header().Get("Server-Timing")
+	if !strings.Contains(timingHeader, "descr=\"49901 timers dropped\"") {
+		t.Error("Server-Timing does not report the dropped timers")
+	}
+	if strings.Count(timingHeader, "descr=") != 101 {
+		t.Errorf("Server-Timing has %d timers, expected 101", strings.Count(timingHeader, "descr="))
+	}
+}
+
+func TestMiddlewareBudgets(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := timers.NewManualClock(time.Unix(1644884400, 0))
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timer := timers.From(r.Context()).New("lookup").Start()
+		clock.Advance(60 * time.Millisecond)
+		timer.Stop()
+	})
+
+	var over []string
+	middleware := timers.Middleware(handler, timers.MiddlewareOptions{
+		Clock:        clock,
+		Budgets:      map[string]time.Duration{"lookup": 50 * time.Millisecond},
+		OnOverBudget: func(t timers.Timer) { over = append(over, t.String()) },
+	})
+	middleware.ServeHTTP(rr, req)
+	if len(over) != 1 || over[0] != "lookup: 60.000ms over budget:(50.000ms)" {
+		t.Errorf("Over budget function was called with %v", over)
+	}
+	if !strings.Contains(rr.Header().Get("Server-Timing"), ";budget=50.000;over_budget=1") {
+		t.Errorf("Server-Timing does not include the budget: %s", rr.Header().Get("Server-Timing"))
+	}
+}
+
+func TestMiddlewareWatchdog(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := timers.NewManualClock(time.Unix(1644884400, 0))
+	found := make(chan string, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hang until the watchdog finds the request
+		clock.Advance(time.Minute)
+		select {
+		case <-found:
+		case <-time.After(5 * time.Second):
+			t.Error("Watchdog didn't report the hung request")
+		}
+	})
+
+	middleware := timers.Middleware(handler, timers.MiddlewareOptions{
+		Clock: clock,
+		Watchdog: timers.WatchdogOptions{
+			Threshold: time.Second,
+			Interval:  time.Millisecond,
+			Callback:  func(st timers.StuckTimer) { found <- st.Path },
+		},
+	})
+	middleware.ServeHTTP(rr, req)
+}
+
+func TestMiddlewareLeaks(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timers.From(r.Context()).New("forgotten").Start()
+		timers.From(r.Context()).New("not started")
+	})
+
+	var leaks timers.Leaks
+	middleware := timers.Middleware(handler, timers.MiddlewareOptions{
+		Debug:    true,
+		Callback: func(s *timers.TimerSet) { leaks = s.Leaks() },
+	})
+	middleware.ServeHTTP(rr, req)
+	if len(leaks) != 2 || leaks[0].Path != "forgotten" || leaks[0].Kind != timers.LeakNotStopped ||
+		leaks[1].Path != "not started" || leaks[1].Kind != timers.LeakNotStarted {
+		t.Fatalf("Leaks were\n%s", leaks)
+	}
+	if !strings.Contains(leaks[0].Origin, "middleware_test.go:") {
+		t.Errorf("Leak's origin was %s", leaks[0].Origin)
+	}
+}
+
+func TestMiddlewareStopOnCancel(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timer := timers.From(r.Context()).New("downstream").Start()
+		cancel() // The client went away
+		for deadline := time.Now().Add(5 * time.Second); timer.IsRunning(); {
+			if time.Now().After(deadline) {
+				t.Fatal("Timer wasn't stopped when the request was cancelled")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	middleware := timers.Middleware(handler, timers.MiddlewareOptions{StopOnCancel: true})
+	middleware.ServeHTTP(rr, req)
+	timingHeader := rr.Header().Get("Server-Timing")
+	if !strings.Contains(timingHeader, `descr="downstream"`) || !strings.Contains(timingHeader, `;error="context canceled"`) {
+		t.Errorf("Server-Timing does not show the cancelled timer: %s", timingHeader)
+	}
+}
@@ -0,0 +1,57 @@
+package timers
+
+import "errors"
+
+// Records that the work the timer is measuring failed. The timer keeps running, use
+// StopWithError() to do both at once. If the timer has already failed, the first error is
+// kept, as it is most likely the cause. A nil error does nothing.
+func (t *Timer) Fail(err error) *Timer {
+	if err == nil || t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
+	if t.err == nil {
+		t.err = err
+	}
+	return t
+}
+
+// Records the error (if any) and stops the timer.
+func (t *Timer) StopWithError(err error) *Timer {
+	return t.Fail(err).Stop()
+}
+
+// Stops the timer, recording the error that errp points to, if any. This is intended to be
+// deferred in functions with a named error return, as the error is read when the function
+// returns:
+//  func Lookup(ctx context.Context) (err error) {
+//      defer timers.From(ctx).New("Lookup").Start().StopErr(&err)
+//      ...
+//  }
+func (t *Timer) StopErr(errp *error) *Timer {
+	if errp != nil {
+		t.Fail(*errp)
+	}
+	return t.Stop()
+}
+
+// Returns the error the timer failed with, or nil if it hasn't failed.
+func (t *Timer) Err() error {
+	t.lock()
+	defer t.unlock()
+	return t.err
+}
+
+// Returns true if the timer has failed.
+func (t *Timer) Failed() bool {
+	return t.Err() != nil
+}
+
+// Imported timers only have the error message, not the original error.
+func errorFromMessage(msg *string) error {
+	if msg == nil {
+		return nil
+	}
+	return errors.New(*msg)
+}
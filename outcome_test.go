@@ -0,0 +1,95 @@
+package timers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errBoom = errors.New("boom")
+
+func TestFail(t *testing.T) {
+	timer := newSet().New("fail").Start()
+	timer.Fail(nil)
+	if timer.Failed() {
+		t.Error("Fail(nil) marked the timer as failed")
+	}
+	timer.Fail(errBoom).Fail(errors.New("second"))
+	if timer.Err() != errBoom {
+		t.Errorf("Expected the first error to be kept, got %v", timer.Err())
+	}
+	if !timer.IsRunning() {
+		t.Error("Fail() stopped the timer")
+	}
+	if !strings.Contains(timer.copy().String(), "error:(boom)") {
+		t.Errorf("String() did not contain the error: %s", timer.copy().String())
+	}
+}
+
+func TestStopWithError(t *testing.T) {
+	timer := newSet().New("fail").Start().StopWithError(errBoom)
+	if timer.IsRunning() {
+		t.Error("StopWithError() didn't stop the timer")
+	}
+	if !timer.Failed() {
+		t.Error("StopWithError() didn't record the error")
+	}
+}
+
+func TestStopErr(t *testing.T) {
+	s := newSet()
+	fn := func(fail bool) (err error) {
+		defer s.New("fn").Start().StopErr(&err)
+		if fail {
+			return errBoom
+		}
+		return nil
+	}
+	fn(false)
+	fn(true)
+	all := s.All()
+	if all[0].Failed() || all[0].IsRunning() {
+		t.Error("Successful call was recorded as failed or left running")
+	}
+	if all[1].Err() != errBoom || all[1].IsRunning() {
+		t.Error("Failed call was not recorded as failed or left running")
+	}
+	s.New("nil").Start().StopErr(nil)
+}
+
+func TestFailExport(t *testing.T) {
+	s := newSet()
+	s.New("ok").Start().Stop()
+	s.New("failed").Start().StopWithError(errors.New(`bad "thing"`))
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	t.Log(header)
+	if !strings.Contains(header, `id=2;error="bad \"thing\""`) {
+		t.Error("Header did not contain the failed timer's error")
+	}
+	if strings.Count(header, "error=") != 1 {
+		t.Error("Header contained an error for a successful timer")
+	}
+
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(bytes), `"error":"bad \"thing\""`) != 1 {
+		t.Errorf("JSON did not contain the error once: %s", bytes)
+	}
+	var s2 TimerSet
+	if err := json.Unmarshal(bytes, &s2); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Find("ok").Failed() {
+		t.Error("Successful timer failed after round trip")
+	}
+	if e := s2.Find("failed").Err(); e == nil || e.Error() != `bad "thing"` {
+		t.Errorf("Failed timer had the wrong error after round trip: %v", e)
+	}
+}
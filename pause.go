@@ -0,0 +1,74 @@
+package timers
+
+import "time"
+
+// Pauses the timer, so that the time until Resume() is not counted as active time. This is
+// useful to exclude time spent waiting on things that aren't the code being measured, like a
+// callback or I/O. The wall clock Duration() still includes the paused time, use
+// ActiveDuration() for the time spent not paused.
+// Pausing a timer that isn't running, or is already paused, does nothing.
+func (t *Timer) Pause() *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
+	if !t.running() || t.resumed.IsZero() {
+		return t
+	}
+	t.active += t.now().Sub(t.resumed)
+	t.resumed = time.Time{}
+	t.pauses++
+	return t
+}
+
+// Resumes a paused timer. If the timer is not paused this function does nothing.
+func (t *Timer) Resume() *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
+	if !t.running() || !t.resumed.IsZero() {
+		return t
+	}
+	t.resumed = t.now()
+	return t
+}
+
+// Returns true if the timer is paused. A paused timer is still running, as it hasn't been
+// stopped, so IsRunning() will also return true.
+func (t *Timer) IsPaused() bool {
+	t.lock()
+	defer t.unlock()
+	return t.isPaused()
+}
+
+// Caller must hold the lock.
+func (t *Timer) isPaused() bool {
+	return t.running() && t.resumed.IsZero()
+}
+
+// Returns how long the timer has been active, that is the Duration() less any time the timer
+// was paused. For a timer that has never been paused, this is the same as Duration().
+func (t *Timer) ActiveDuration() time.Duration {
+	t.lock()
+	defer t.unlock()
+	return t.activeDuration()
+}
+
+// Caller must hold the lock.
+func (t *Timer) activeDuration() time.Duration {
+	if t.pauses == 0 {
+		return t.wallDuration()
+	}
+	if t.running() && !t.resumed.IsZero() {
+		return t.active + t.now().Sub(t.resumed)
+	}
+	return t.active
+}
+
+// Returns the active duration in milliseconds, as a float rounded to 3 decimal places.
+func (t *Timer) ActiveMilliseconds() float64 {
+	return durationMs(t.ActiveDuration())
+}
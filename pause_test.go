@@ -0,0 +1,106 @@
+package timers
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPauseResume(t *testing.T) {
+	timer := newSet().New("pause")
+	timer.Pause()
+	if timer.IsPaused() {
+		t.Error("Timer that hasn't started was paused")
+	}
+	timer.Start()
+	time.Sleep(2 * time.Millisecond)
+	timer.Pause().Pause()
+	if !timer.IsPaused() || !timer.IsRunning() {
+		t.Error("Paused timer should be paused and running")
+	}
+	if !strings.Contains(timer.copy().String(), "Paused") {
+		t.Error("Paused timer's String() does not say Paused")
+	}
+	active := timer.ActiveDuration()
+	time.Sleep(5 * time.Millisecond)
+	if timer.ActiveDuration() != active {
+		t.Error("Active time elapsed while paused")
+	}
+	timer.Resume().Resume()
+	if timer.IsPaused() {
+		t.Error("Resumed timer is still paused")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if timer.ActiveDuration() <= active {
+		t.Error("Active time didn't elapse after resuming")
+	}
+	timer.Stop()
+	if timer.Duration()-timer.ActiveDuration() < 5*time.Millisecond {
+		t.Errorf("Paused time wasn't excluded: duration %s active %s", timer.Duration(), timer.ActiveDuration())
+	}
+	timer.Resume()
+	if timer.IsRunning() {
+		t.Error("Resume() restarted a stopped timer")
+	}
+	if !strings.Contains(timer.copy().String(), "(active ") {
+		t.Error("Stopped paused timer's String() does not include the active time")
+	}
+}
+
+func TestStopWhilePaused(t *testing.T) {
+	timer := newSet().New("pause").Start().Pause()
+	active := timer.ActiveDuration()
+	time.Sleep(time.Millisecond)
+	timer.Stop()
+	if timer.ActiveDuration() != active {
+		t.Error("Stopping a paused timer changed its active time")
+	}
+	if timer.IsPaused() {
+		t.Error("Stopped timer is still paused")
+	}
+}
+
+func TestNeverPaused(t *testing.T) {
+	timer := newSet().New("pause").Start().nap().Stop()
+	if timer.ActiveDuration() != timer.Duration() {
+		t.Error("Timer that was never paused has an active duration different to its duration")
+	}
+	if strings.Contains(timer.copy().String(), "active") {
+		t.Error("Timer that was never paused shows active time")
+	}
+}
+
+func TestPauseExport(t *testing.T) {
+	s := newSet()
+	s.New("unpaused").Start().Stop()
+	timer := s.New("paused").Start().Pause().Resume().Stop()
+	timer.duration = 10 * time.Millisecond
+	timer.active = 4 * time.Millisecond
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if strings.Count(header, ";active=4.000") != 1 || strings.Count(header, "active=") != 1 {
+		t.Errorf("Header did not contain only the paused timer's active time: %s", header)
+	}
+
+	bytes, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bytes), `"duration":10000000,"active":4000000,"pauses":1`) {
+		t.Errorf("JSON did not contain the active time: %s", bytes)
+	}
+	var s2 TimerSet
+	if err := json.Unmarshal(bytes, &s2); err != nil {
+		t.Fatal(err)
+	}
+	if s2.Find("paused").ActiveDuration() != 4*time.Millisecond {
+		t.Errorf("Active time didn't survive a round trip: %s", s2.Find("paused").ActiveDuration())
+	}
+	if d := s2.Find("unpaused"); d.ActiveDuration() != d.Duration() {
+		t.Error("Unpaused timer gained an active time in a round trip")
+	}
+}
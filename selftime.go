@@ -0,0 +1,75 @@
+package timers
+
+import (
+	"sort"
+	"time"
+)
+
+// Returns the self (or exclusive) time of the timer: its Duration() less the time covered by
+// the timers in its child TimerSet (see NewContextWithTimer and Wrap). Children that ran at the
+// same time are only counted once, as it is the union of the children's time that is removed,
+// not the sum. For a timer without children this is the same as Duration().
+func (t *Timer) SelfDuration() time.Duration {
+	c := t.copy()
+	return c.selfDuration()
+}
+
+// Caller must hold the lock, or be using a copy.
+func (t *Timer) selfDuration() time.Duration {
+	if t.start.IsZero() {
+		return 0
+	}
+	d := t.wallDuration()
+	if t.subtimer == nil {
+		return d
+	}
+	start, end := t.start, t.start.Add(d)
+
+	var intervals []interval
+	for _, i := range t.subtimer.intervals() {
+		// Only the part of the child inside the parent counts
+		if i.start.Before(start) {
+			i.start = start
+		}
+		if i.end.After(end) {
+			i.end = end
+		}
+		if i.end.After(i.start) {
+			intervals = append(intervals, i)
+		}
+	}
+	sort.Slice(intervals, func(a, b int) bool {
+		return intervals[a].start.Before(intervals[b].start)
+	})
+
+	var covered time.Duration
+	var last time.Time
+	for _, i := range intervals {
+		if i.start.Before(last) {
+			i.start = last
+		}
+		if i.end.After(i.start) {
+			covered += i.end.Sub(i.start)
+			last = i.end
+		}
+	}
+	return d - covered
+}
+
+// The time a timer ran, from when it started until it stopped, or until now if it is running.
+type interval struct{ start, end time.Time }
+
+// Returns the intervals of the timers in the set that have started, apart from marks. The timers
+// NewContext creates to hold a child TimerSet are never started, so the timers in their child
+// TimerSet are used instead.
+func (s *TimerSet) intervals() []interval {
+	var intervals []interval
+	for _, c := range s.All() {
+		if c.group && c.subtimer != nil {
+			intervals = append(intervals, c.subtimer.intervals()...)
+		} else if !c.start.IsZero() && !c.mark {
+			intervals = append(intervals, interval{c.start, c.start.Add(c.wallDuration())})
+		}
+	}
+	return intervals
+}
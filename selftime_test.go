@@ -0,0 +1,124 @@
+package timers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Returns a stopped parent timer whose children overlap: it runs from 0 to 100ms, "a" from
+// 10ms to 40ms, "b" from 30ms to 50ms, and "c" from 90ms until after the parent has stopped.
+func selfTimeParent(clock *ManualClock) *Timer {
+	ctx := NewContext(context.Background())
+	From(ctx).SetClock(clock)
+	ctx, parent := NewContextWithTimer(ctx, "parent")
+	s := From(ctx)
+	parent.Start()
+	clock.Advance(10 * time.Millisecond)
+	a := s.New("a").Start()
+	clock.Advance(20 * time.Millisecond)
+	b := s.New("b").Start()
+	clock.Advance(10 * time.Millisecond)
+	a.Stop()
+	clock.Advance(10 * time.Millisecond)
+	b.Stop()
+	s.New("not started")
+	clock.Advance(40 * time.Millisecond)
+	c := s.New("c").Start()
+	clock.Advance(10 * time.Millisecond)
+	parent.Stop()
+	clock.Advance(10 * time.Millisecond)
+	c.Stop()
+	return parent
+}
+
+func TestSelfDuration(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	parent := selfTimeParent(clock)
+	// a and b overlap, so cover 10ms-50ms, and only 10ms of c is inside the parent
+	if d := parent.SelfDuration(); d != 50*time.Millisecond {
+		t.Errorf("Parent's self time was %s not 50ms", d)
+	}
+	if d := parent.Duration(); d != 100*time.Millisecond {
+		t.Errorf("Parent's duration was %s not 100ms", d)
+	}
+
+	child := parent.sub().Find("a")
+	if child.SelfDuration() != child.Duration() {
+		t.Errorf("Timer without children has self time %s, not its duration %s", child.SelfDuration(), child.Duration())
+	}
+	if d := parent.sub().Find("not started").SelfDuration(); d != 0 {
+		t.Errorf("Timer that hasn't started has self time %s", d)
+	}
+}
+
+func TestSelfDurationRunning(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	From(ctx).SetClock(clock)
+	ctx, parent := NewContextWithTimer(ctx, "parent")
+	parent.Start()
+	From(ctx).New("child").Start()
+	clock.Advance(5 * time.Millisecond)
+	if d := parent.SelfDuration(); d != 0 {
+		t.Errorf("Running parent covered by a running child has self time %s", d)
+	}
+	if d := From(ctx).Find("child").SelfDuration(); d != 5*time.Millisecond {
+		t.Errorf("Running child has self time %s not 5ms", d)
+	}
+}
+
+func TestSelfDurationNewContext(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	From(ctx).SetClock(clock)
+	ctx, parent := NewContextWithTimer(ctx, "parent")
+	parent.Start()
+	clock.Advance(10 * time.Millisecond)
+	// The child's timers are under the "Subtimer" NewContext adds, which is never started
+	ctx = NewContext(ctx)
+	child := From(ctx).New("child").Start()
+	clock.Advance(20 * time.Millisecond)
+	child.Stop()
+	From(NewContext(ctx)).New("grandchild").Start()
+	clock.Advance(30 * time.Millisecond)
+	parent.Stop()
+	if d := parent.SelfDuration(); d != 10*time.Millisecond {
+		t.Errorf("Parent's self time was %s not 10ms", d)
+	}
+}
+
+func TestSelfDurationExported(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	parent := selfTimeParent(clock)
+	s := newSet()
+	s.add(parent)
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"self":50`) {
+		t.Errorf("JSON doesn't include the self time: %s", b)
+	}
+	if strings.Count(string(b), `"self"`) != 1 {
+		t.Errorf("JSON includes self time for timers without children: %s", b)
+	}
+	var imported TimerSet
+	if err := json.Unmarshal(b, &imported); err != nil {
+		t.Fatal(err)
+	}
+	if d := imported.Find("parent").SelfDuration(); d != 50*time.Millisecond {
+		t.Errorf("Imported parent's self time was %s not 50ms", d)
+	}
+
+	response := httptest.NewRecorder()
+	s.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.Contains(header, `parent;descr="parent";dur=100.000;start=1644884400000;parent=0;id=1;self=50.000,`) {
+		t.Errorf("Header doesn't include the self time: %s", header)
+	}
+}
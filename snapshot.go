@@ -0,0 +1,90 @@
+package timers
+
+import (
+	"sync"
+	"time"
+)
+
+// Returns a copy of the whole tree taken at a single instant. Every TimerSet and Timer in the tree
+// is locked while it is copied, so the snapshot is consistent even while other go routines are
+// starting and stopping timers: a parent is never stopped before its children, and the children's
+// durations add up. Timers that are running keep running in the snapshot, but their Duration()
+// is frozen at the time of the snapshot, which is snapshot.Clock().Now().
+//
+// The snapshot shares nothing with the original tree, and can't be changed: creating, starting or
+// stopping its timers does nothing, as does grafting it with Attach or Merge. It is still enabled,
+// and can be exported, compared with Diff and so on. It is what MarshalJSON, AddHeader, String,
+// Summary and CriticalPath work from.
+//  snapshot := timers.From(ctx).Snapshot()
+//  log.Printf("Timers after %s:\n%s", time.Since(start), snapshot)
+func (s *TimerSet) Snapshot() *TimerSet {
+	if s.readOnly() {
+		return s
+	}
+	clock := &frozenClock{}
+	var locked []sync.Locker
+	snapshot := s.freeze(clock, &locked)
+	// Everything is still locked, so nothing has changed since the copy was taken
+	if s.clock == nil {
+		clock.now = time.Now()
+	} else {
+		clock.now = s.clock.Now()
+	}
+	for i := len(locked) - 1; i >= 0; i-- {
+		locked[i].Unlock()
+	}
+	return snapshot
+}
+
+// Copies the set and its timers, leaving them locked, with their locks appended to locked.
+func (s *TimerSet) freeze(clock Clock, locked *[]sync.Locker) *TimerSet {
+	s.mu.Lock()
+	*locked = append(*locked, &s.mu)
+	snapshot := &TimerSet{
+		frozen:    true,
+		timers:    make([]*Timer, len(s.timers)),
+		clock:     clock,
+		limits:    s.limits,
+		depth:     s.depth,
+		dropped:   s.dropped,
+		parentId:  s.parentId,
+		droppedId: s.droppedId,
+	}
+	for i, t := range s.timers {
+		if t.mu != nil {
+			t.mu.Lock()
+			*locked = append(*locked, t.mu)
+		}
+		c := t.copyLocked()
+		c.frozen = true
+		if _, frozen := c.clock.(*frozenClock); !frozen {
+			// Imported running timers are already frozen, at the time they were exported
+			c.clock = clock
+		}
+		c.onOverBudget = nil
+		if t.subtimer != nil {
+			c.subtimer = t.subtimer.freeze(clock, locked)
+		}
+		snapshot.timers[i] = &c
+	}
+	return snapshot
+}
+
+// Returns true if the set can't be changed, as it is disabled or is a Snapshot.
+func (s *TimerSet) readOnly() bool {
+	return s.disabled || s.frozen
+}
+
+// Returns true if the timer can't be changed, as it is disabled or part of a Snapshot.
+func (t *Timer) readOnly() bool {
+	return t.disabled || t.frozen
+}
+
+// A Clock that always returns the time a snapshot was taken.
+type frozenClock struct {
+	now time.Time // Set once, before the snapshot is returned
+}
+
+func (c *frozenClock) Now() time.Time {
+	return c.now
+}
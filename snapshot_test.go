@@ -0,0 +1,121 @@
+package timers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock)
+	s.New("done").Start().Tag("a")
+	clock.Advance(time.Millisecond)
+	s.Find("done").Stop()
+	ctx, request := NewContextWithTimer(ctx, "request")
+	request.Start()
+	child := From(ctx).New("child").Start()
+	clock.Advance(2 * time.Millisecond)
+
+	snapshot := s.Snapshot()
+	taken := clock.Now()
+	clock.Advance(time.Second)
+	child.Tag("later").Stop()
+	From(ctx).New("later")
+	s.Find("done").Tag("later")
+
+	if now := snapshot.Clock().Now(); !now.Equal(taken) {
+		t.Errorf("Snapshot was taken at %s, not %s", now, taken)
+	}
+	str := "done: 1.000ms tags:(a)\nrequest: Running\nchild: Running"
+	if snapshot.String() != str {
+		t.Errorf("Snapshot changed with the original tree:\n%s", snapshot)
+	}
+	frozen := snapshot.FindPath("request/child")
+	if !frozen.IsRunning() || frozen.Duration() != 2*time.Millisecond || snapshot.Find("request").Duration() != 2*time.Millisecond {
+		t.Errorf("Running timers weren't frozen: %s %s", frozen.Duration(), snapshot.Find("request").Duration())
+	}
+
+	// Nothing about a snapshot can be changed
+	snapshot.New("new").Start()
+	frozen.Tag("tag").Stop()
+	snapshot.Find("request").Attr("key", 1).Stop()
+	if snapshot.String() != str || !frozen.IsRunning() {
+		t.Errorf("Snapshot was changed:\n%s", snapshot)
+	}
+	if snapshot.Snapshot() != snapshot || noopSet.Snapshot() != noopSet {
+		t.Error("Snapshot of an immutable set wasn't itself")
+	}
+}
+
+func TestSnapshotExport(t *testing.T) {
+	s := SetFromContext(NewContext(context.Background()))
+	s.New("db").Start().Stop()
+	snapshot := s.Snapshot()
+	if !snapshot.Enabled() {
+		t.Error("Snapshot isn't enabled")
+	}
+	response := httptest.NewRecorder()
+	snapshot.AddHeader(response)
+	header := response.Result().Header.Get("Server-Timing")
+	if !strings.HasPrefix(header, `db;descr="db";dur=`) {
+		t.Errorf("Snapshot wasn't exported in the header: %q", header)
+	}
+
+	// The snapshot doesn't share the tree's IDs
+	for _, timer := range snapshot.flatTree(false) {
+		if timer.ids != nil {
+			t.Errorf("Snapshot timer %s shares the tree's IDs", timer.name)
+		}
+	}
+	if id := s.New("next").ID(); id != 2 {
+		t.Errorf("Timer after the snapshot has ID %d, not 2", id)
+	}
+}
+
+func TestSnapshotConsistent(t *testing.T) {
+	ctx := NewContext(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ctx, parent := NewContextWithTimer(ctx, "parent")
+				parent.Start()
+				From(ctx).New("child").Start().Stop()
+				parent.Stop()
+			}
+		}()
+	}
+	check := func() {
+		From(ctx).Snapshot().Tree(func(timer Timer, depth int, _ *TimerSet) {
+			if timer.name != "parent" || timer.IsRunning() {
+				return
+			}
+			for _, child := range timer.Children() {
+				if child.IsRunning() {
+					t.Errorf("Snapshot has a stopped parent with a running child")
+				}
+			}
+		})
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			check()
+			return
+		default:
+			check()
+		}
+	}
+}
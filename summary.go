@@ -0,0 +1,146 @@
+package timers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Stats are the aggregate statistics for a group of timers with the same name (and tag, when
+// grouped by tag). Only timers that have started are counted, running timers are counted with
+// their duration so far. Marks, and the timer reporting how many were dropped (see Limits), are
+// not timings, so they aren't counted.
+type Stats struct {
+	Name  string
+	Tag   string // Only set by SummaryByTag
+	Count int
+	Total time.Duration
+	Mean  time.Duration
+	Min   time.Duration
+	Max   time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+
+	durations []time.Duration // sorted
+}
+
+// A Summary is a list of Stats, ordered by total duration, largest first.
+type Summary []Stats
+
+// Groups every timer in the tree by name, and returns the statistics for each name. This is
+// useful when the same downstream is called many times in one request.
+func (s *TimerSet) Summary() Summary {
+	return s.summarise(false)
+}
+
+// Like Summary, but groups the timers by name and tag. A timer with more than one tag is
+// counted once for each tag, and timers without a tag are grouped with an empty tag.
+func (s *TimerSet) SummaryByTag() Summary {
+	return s.summarise(true)
+}
+
+func (s *TimerSet) summarise(byTag bool) Summary {
+	type key struct{ name, tag string }
+	groups := make(map[key]*Stats)
+	var order []key
+	add := func(k key, d time.Duration) {
+		st, ok := groups[k]
+		if !ok {
+			st = &Stats{Name: k.name, Tag: k.tag}
+			groups[k] = st
+			order = append(order, k)
+		}
+		st.durations = append(st.durations, d)
+	}
+	for _, t := range s.Snapshot().AllDeep() {
+		if t.start.IsZero() || t.mark || t.dropped > 0 {
+			continue
+		}
+		d := t.Duration()
+		if !byTag || len(t.tags) == 0 {
+			add(key{name: t.name}, d)
+			continue
+		}
+		for _, tag := range t.tags {
+			add(key{name: t.name, tag: tag}, d)
+		}
+	}
+
+	summary := make(Summary, len(order))
+	for i, k := range order {
+		st := groups[k]
+		st.calculate()
+		summary[i] = *st
+	}
+	sort.SliceStable(summary, func(i, j int) bool {
+		return summary[i].Total > summary[j].Total
+	})
+	return summary
+}
+
+// Calculates the statistics from the durations.
+func (st *Stats) calculate() {
+	sort.Slice(st.durations, func(i, j int) bool { return st.durations[i] < st.durations[j] })
+	st.Count = len(st.durations)
+	st.Total = 0
+	for _, d := range st.durations {
+		st.Total += d
+	}
+	st.Mean = st.Total / time.Duration(st.Count)
+	st.Min = st.durations[0]
+	st.Max = st.durations[st.Count-1]
+	st.P50 = st.Percentile(50)
+	st.P90 = st.Percentile(90)
+	st.P99 = st.Percentile(99)
+}
+
+// Returns the duration at the provided percentile (0 to 100), using the nearest rank.
+func (st Stats) Percentile(p float64) time.Duration {
+	if len(st.durations) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(st.durations))))
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(st.durations) {
+		rank = len(st.durations)
+	}
+	return st.durations[rank-1]
+}
+
+// Renders the summary as a text table, with durations in milliseconds. The tag column is only
+// included if any of the stats have a tag.
+func (sum Summary) String() string {
+	withTag := false
+	for _, st := range sum {
+		if st.Tag != "" {
+			withTag = true
+		}
+	}
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	header := "Name\tCount\tTotal\tMean\tMin\tMax\tP50\tP90\tP99"
+	if withTag {
+		header = "Name\tTag\tCount\tTotal\tMean\tMin\tMax\tP50\tP90\tP99"
+	}
+	fmt.Fprintln(w, header)
+	for _, st := range sum {
+		name := st.Name + "\t"
+		if withTag {
+			name += st.Tag + "\t"
+		}
+		fmt.Fprintf(w, "%s%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", name, st.Count, fmtMs(st.Total), fmtMs(st.Mean),
+			fmtMs(st.Min), fmtMs(st.Max), fmtMs(st.P50), fmtMs(st.P90), fmtMs(st.P99))
+	}
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Formats a duration as milliseconds for the text reports.
+func fmtMs(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,113 @@
+package timers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Creates timers named name, taking each of the durations in milliseconds.
+func summaryTimers(ctx context.Context, clock *ManualClock, name string, tag string, ms ...int) {
+	for _, m := range ms {
+		t := From(ctx).New(name)
+		if tag != "" {
+			t.Tag(tag)
+		}
+		t.Start()
+		clock.Advance(time.Duration(m) * time.Millisecond)
+		t.Stop()
+	}
+}
+
+func TestSummary(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	From(ctx).SetClock(clock)
+	summaryTimers(ctx, clock, "db", "", 5, 1, 3)
+	From(ctx).Wrap(ctx, "work", func(ctx context.Context) {
+		summaryTimers(ctx, clock, "db", "", 2, 4)
+		summaryTimers(ctx, clock, "cache", "", 1)
+	})
+	From(ctx).New("not started")
+
+	sum := From(ctx).Summary()
+	if len(sum) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(sum))
+	}
+	db := sum[0]
+	if db.Name != "db" || db.Count != 5 || db.Total != 15*time.Millisecond || db.Mean != 3*time.Millisecond ||
+		db.Min != time.Millisecond || db.Max != 5*time.Millisecond || db.P50 != 3*time.Millisecond ||
+		db.P90 != 5*time.Millisecond || db.P99 != 5*time.Millisecond {
+		t.Errorf("db stats are wrong: %+v", db)
+	}
+	if db.Percentile(20) != time.Millisecond || db.Percentile(0) != time.Millisecond || db.Percentile(200) != 5*time.Millisecond {
+		t.Error("db percentiles are wrong")
+	}
+	if sum[1].Name != "work" || sum[2].Name != "cache" {
+		t.Errorf("Summary isn't ordered by total: %s, %s", sum[1].Name, sum[2].Name)
+	}
+	if (Stats{}).Percentile(50) != 0 {
+		t.Error("Empty stats has a percentile")
+	}
+
+	expect := "" +
+		"Name   Count  Total     Mean     Min      Max      P50      P90      P99\n" +
+		"db     5      15.000ms  3.000ms  1.000ms  5.000ms  3.000ms  5.000ms  5.000ms\n" +
+		"work   1      7.000ms   7.000ms  7.000ms  7.000ms  7.000ms  7.000ms  7.000ms\n" +
+		"cache  1      1.000ms   1.000ms  1.000ms  1.000ms  1.000ms  1.000ms  1.000ms"
+	if sum.String() != expect {
+		t.Errorf("Summary table was\n%s\nexpected\n%s", sum.String(), expect)
+	}
+}
+
+func TestSummaryMarks(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	ctx := NewContext(context.Background())
+	From(ctx).SetClock(clock).SetLimits(Limits{MaxTimers: 3})
+	summaryTimers(ctx, clock, "db", "", 4)
+	From(ctx).Mark("db")
+	summaryTimers(ctx, clock, "db", "", 2, 6, 8)
+
+	sum := From(ctx).Summary()
+	if len(sum) != 1 {
+		t.Fatalf("Expected only the db group, got %s", sum)
+	}
+	if db := sum[0]; db.Count != 2 || db.Min != 2*time.Millisecond || db.Mean != 3*time.Millisecond {
+		t.Errorf("Mark or dropped timers were counted: %+v", db)
+	}
+}
+
+func TestSummaryByTag(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	s := newSet().SetClock(clock)
+	ctx := context.WithValue(context.Background(), timerctx("timers"), s)
+	summaryTimers(ctx, clock, "db", "primary", 4, 6)
+	summaryTimers(ctx, clock, "db", "replica", 1)
+	summaryTimers(ctx, clock, "db", "", 2)
+	s.New("db").Tag("primary").Tag("slow").Start()
+	clock.Advance(10 * time.Millisecond)
+
+	sum := s.SummaryByTag()
+	if len(sum) != 4 {
+		t.Fatalf("Expected 4 groups, got %d: %v", len(sum), sum)
+	}
+	if sum[0].Tag != "primary" || sum[0].Count != 3 || sum[0].Total != 20*time.Millisecond {
+		t.Errorf("primary stats are wrong: %+v", sum[0])
+	}
+	if sum[1].Tag != "slow" || sum[1].Count != 1 {
+		t.Errorf("slow stats are wrong: %+v", sum[1])
+	}
+	if sum[2].Tag != "" || sum[2].Count != 1 || sum[3].Tag != "replica" {
+		t.Errorf("Untagged or replica stats are wrong: %+v", sum[2:])
+	}
+
+	expect := "" +
+		"Name  Tag      Count  Total     Mean      Min       Max       P50       P90       P99\n" +
+		"db    primary  3      20.000ms  6.667ms   4.000ms   10.000ms  6.000ms   10.000ms  10.000ms\n" +
+		"db    slow     1      10.000ms  10.000ms  10.000ms  10.000ms  10.000ms  10.000ms  10.000ms\n" +
+		"db             1      2.000ms   2.000ms   2.000ms   2.000ms   2.000ms   2.000ms   2.000ms\n" +
+		"db    replica  1      1.000ms   1.000ms   1.000ms   1.000ms   1.000ms   1.000ms   1.000ms"
+	if sum.String() != expect {
+		t.Errorf("Summary table was\n%s\nexpected\n%s", sum.String(), expect)
+	}
+}
@@ -25,8 +25,23 @@ import (
 // of functions to create, retrieve, and export timers. Creation of a TimerSet is done with
 // the NewContext function.
 type TimerSet struct {
-	mu     sync.Mutex
-	timers []*Timer
+	disabled     bool // See SetEnabled. Never changes, so it can be read without the lock
+	frozen       bool // A Snapshot, which can't be changed. Never changes, like disabled
+	mu           sync.Mutex
+	timers       []*Timer
+	clock        Clock
+	limits       Limits
+	tree         *timerCount // Shared by the tree, when Limits.MaxTotal is set
+	depth        int
+	dropped      int
+	dropTo       *TimerSet                // If set, this TimerSet is detached and drops every timer, see Limits
+	budgets      map[string]time.Duration // Shared with child TimerSets, see SetBudget
+	onOverBudget func(Timer)
+	debug        bool       // See SetDebug
+	ids          *idCounter // Shared by the tree, see Timer.ID
+	parentId     int        // The ID of the timer this set is under
+	droppedId    int        // The ID of the synthetic dropped timer, see Limits
+	attached     bool       // Under a timer, as its subtimer, so it can't be attached to another
 }
 
 // An individual timer is used to measure, well, time elapsed, and is stored in a timerset.
@@ -35,16 +50,40 @@ type TimerSet struct {
 //
 // To get a Timer, you call New from a TimerSet, retrieved from the context:
 //  timers.From(ctx).New("Blah")
+//
+// Timers created by a TimerSet are safe for concurrent use, each one guards its own state with
+// a lock. A zero Timer{} has no lock, and should only be used from a single go routine.
 type Timer struct {
-	name     string
-	start    time.Time
-	duration time.Duration
-	tags     []string
-	subtimer *TimerSet
-	// For export use only -- not at all guarenteed accurate except as copies being
-	// generated for exporting tree
-	id       int
-	parentId int
+	disabled     bool // See SetEnabled. Never changes, so it can be read without the lock
+	frozen       bool // Part of a Snapshot, which can't be changed. Never changes, like disabled
+	mu           *sync.Mutex
+	name         string
+	start        time.Time
+	duration     time.Duration
+	tags         []string
+	attrs        []Attr
+	err          error
+	laps         []Lap
+	events       []Event
+	counters     []Counter
+	active       time.Duration // Active time up to the last pause, see Pause()
+	resumed      time.Time     // When the timer was last started or resumed, zero while paused
+	pauses       int
+	stopped      bool
+	mark         bool // See TimerSet.Mark()
+	group        bool // Created by NewContext to hold the child TimerSet, so never started
+	stoppedByAll bool // Stopped by StopAllTimers rather than Stop(), see Leaks
+	origin       string
+	lane         int // The lane the timer ran in, see Group
+	budget       time.Duration
+	overBudget   bool
+	onOverBudget func(Timer)
+	clock        Clock
+	dropped      int // Only set on the synthetic "N timers dropped" timer
+	subtimer     *TimerSet
+	id           int        // See ID()
+	parentId     int        // See ParentID()
+	ids          *idCounter // The tree's IDs, for Attach
 }
 
 type timerctx string
@@ -58,10 +97,19 @@ var GlobalTimers *TimerSet = &TimerSet{}
 // struct that will not be attached to the current context.
 func NewContext(ctx context.Context) context.Context {
 	existingSet := From(ctx)
-	newSet := &TimerSet{}
+	if existingSet.readOnly() {
+		return ctx
+	}
+	if SetFromContext(ctx) == nil {
+		// There is no tree to add to, so the new TimerSet is the top of one
+		return context.WithValue(ctx, timerctx("timers"), newSet())
+	}
+	newSet := existingSet.newChild()
 	ctx = context.WithValue(ctx, timerctx("timers"), newSet)
-	t := existingSet.New("Subtimer")
+	t := newTimer("Subtimer")
 	t.subtimer = newSet
+	t.group = true
+	existingSet.add(t)
 	return ctx
 }
 
@@ -69,15 +117,18 @@ func NewContext(ctx context.Context) context.Context {
 // context (if any). If the previous context did not have a TimerSet, then the timer is a floating
 // timer (but safe to use). The timer name string can be a formatted string (just like fmt.Printf)
 //
-// TimerSets are threadsafe, you can create new timers across threads using the same TimerSet,
-// however the Timers themselves are not. Don't manipulate the same Timer in different go routines
-// at the same time. You're probably doing it wrong if you find yourself wanting to.
+// TimerSets and Timers are threadsafe, you can create new timers across threads using the same
+// TimerSet, and a Timer may be stopped by a different go routine than the one that started it.
 func NewContextWithTimer(ctx context.Context, name string, a ...interface{}) (context.Context, *Timer) {
 	existingSet := From(ctx)
-	newSet := &TimerSet{}
+	if existingSet.readOnly() {
+		return ctx, noopTimer
+	}
+	newSet := existingSet.newChild()
 	ctx = context.WithValue(ctx, timerctx("timers"), newSet)
-	t := existingSet.New(name, a...)
+	t := newTimer(fmt.Sprintf(name, a...))
 	t.subtimer = newSet
+	existingSet.add(t)
 	return ctx, t
 }
 
@@ -90,6 +141,9 @@ func NewContextWithTimer(ctx context.Context, name string, a ...interface{}) (co
 // Regardless of whether there is a TimerSet in the current context, you will get back a
 // working timer you can use.
 func From(ctx context.Context) *TimerSet {
+	if !Enabled() {
+		return noopSet
+	}
 	s := SetFromContext(ctx)
 	if s == nil {
 		s = newSet()
@@ -107,6 +161,29 @@ func newSet() *TimerSet {
 	return &(TimerSet{})
 }
 
+// Internal function to create a child TimerSet, which inherits this set's clock and limits.
+// If the child would be deeper than Limits.MaxDepth it is detached, and its timers are
+// dropped and counted against this set.
+func (s *TimerSet) newChild() *TimerSet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	child := &TimerSet{
+		clock:        s.clock,
+		limits:       s.limits,
+		tree:         s.tree,
+		depth:        s.depth + 1,
+		dropTo:       s.dropTo,
+		budgets:      s.budgets,
+		onOverBudget: s.onOverBudget,
+		debug:        s.debug,
+		attached:     true, // It is always made the subtimer of a new timer
+	}
+	if child.dropTo == nil && s.limits.MaxDepth > 0 && child.depth > s.limits.MaxDepth {
+		child.dropTo = s
+	}
+	return child
+}
+
 // Get TimerSet from provided context (if any), otherwise return nil.
 //
 // You probably don't want this function. You want to always create a context with a new TimerSet
@@ -125,13 +202,14 @@ func SetFromContext(ctx context.Context) *TimerSet {
 }
 
 // Returns a copy of all timers in this context. Note: These are a copy of the timers, not the
-// original. TimerSets are threadsafe, Timers aren't.
+// original. Each copy is taken while holding that timer's lock, so it is consistent even if the
+// timer is being stopped by another go routine.
 func (s *TimerSet) All() []Timer {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	timers := make([]Timer, len(s.timers))
 	for i := 0; i < len(s.timers); i++ {
-		timers[i] = *s.timers[i]
+		timers[i] = s.timers[i].copy()
 	}
 	return timers
 }
@@ -140,28 +218,27 @@ func (s *TimerSet) All() []Timer {
 // in child contexts (regardless of whether the underlying context)
 // has been canceled.
 func (s *TimerSet) AllDeep() []*Timer {
-	timers, _ := s.flatTree(0, 1)
-	return timers
+	return s.flatTree(false)
 }
 
-// Flattens the tree, making a copy of it, setting the parentId and currentId for
-// timers as it goes.
-func (s *TimerSet) flatTree(parentId, currentId int) ([]*Timer, int) {
+// Flattens the tree, making a copy of it. If withDropped is true, sets that have dropped
+// timers (see Limits) include a synthetic timer reporting how many.
+func (s *TimerSet) flatTree(withDropped bool) []*Timer {
 	srcTimers := s.All()
 	timers := make([]*Timer, len(srcTimers))
 	for i := 0; i < len(srcTimers); i++ {
 		t := srcTimers[i]
-		t.parentId = parentId
-		t.id = currentId
 		if t.subtimer != nil {
-			var subtimers []*Timer
-			subtimers, currentId = t.subtimer.flatTree(currentId, currentId+1)
-			timers = append(timers, subtimers...)
+			timers = append(timers, t.subtimer.flatTree(withDropped)...)
 		}
-		currentId++
 		timers[i] = &t
 	}
-	return timers, currentId
+	if withDropped {
+		if d := s.droppedTimer(); d != nil {
+			timers = append(timers, d)
+		}
+	}
+	return timers
 }
 
 // Walk the timer tree. Since you can create new TimerSets in new contexts, the timers are
@@ -199,17 +276,30 @@ func (s *TimerSet) walkTree(p *TimerSet, depth int, fn func(Timer, int, *TimerSe
 // Some Work. The "Some Work" timer will have 3 sub timers under it.
 //
 func (s *TimerSet) Wrap(ctx context.Context, name string, fn func(context.Context)) {
-	t := s.New(name)
-	ns := &TimerSet{}
-	newCtx := context.WithValue(ctx, timerctx("timers"), ns)
-	t.subtimer = ns
+	newCtx, t := s.wrap(ctx, name)
 	t.Start()
 	fn(newCtx)
 	t.Stop()
 }
 
+// Creates a timer with a child TimerSet, and a context containing the child, for Wrap and Do.
+func (s *TimerSet) wrap(ctx context.Context, name string) (context.Context, *Timer) {
+	if s.readOnly() {
+		return ctx, noopTimer
+	}
+	ns := s.newChild()
+	newCtx := context.WithValue(ctx, timerctx("timers"), ns)
+	t := newTimer(name)
+	t.subtimer = ns
+	if ns.debug {
+		t.origin = callerOrigin(2) // The caller of Wrap() or Do()
+	}
+	s.add(t)
+	return newCtx, t
+}
+
 func (s *TimerSet) String() string {
-	timers := s.AllDeep()
+	timers := s.Snapshot().flatTree(true)
 	var str []string
 	for _, t := range timers {
 		str = append(str, t.String())
@@ -220,28 +310,68 @@ func (s *TimerSet) String() string {
 // Create a new timer with the provided name.
 // Name is a format string (like Printf)
 func (s *TimerSet) New(name string, a ...interface{}) *Timer {
-	name = fmt.Sprintf(name, a...)
-	timer := Timer{
-		name: name,
+	if s.readOnly() {
+		return noopTimer
 	}
-	s.mu.Lock()
-	s.timers = append(s.timers, &timer)
-	s.mu.Unlock()
-	return &timer
+	return s.add(newTimer(fmt.Sprintf(name, a...)))
 }
 
 // Create a new global timer with the provided name.
 // Name is a format string (like Printf)
 // This is a convienance function for timers.GlobalTimers.New(...)
 func New(name string, a ...interface{}) *Timer {
-	name = fmt.Sprintf(name, a...)
-	timer := Timer{
+	if !Enabled() {
+		return noopTimer
+	}
+	return GlobalTimers.add(newTimer(fmt.Sprintf(name, a...)))
+}
+
+// Internal function to create a timer that is safe for concurrent use.
+func newTimer(name string) *Timer {
+	return &Timer{
+		mu:   &sync.Mutex{},
 		name: name,
 	}
-	GlobalTimers.mu.Lock()
-	GlobalTimers.timers = append(GlobalTimers.timers, &timer)
-	GlobalTimers.mu.Unlock()
-	return &timer
+}
+
+// Appends the timer to the set. Any fields the timer needs (such as subtimer) must be set
+// before calling add, as the timer is visible to other go routines as soon as it is added.
+// If the set is over its limits, the timer is dropped and counted instead. It is still
+// returned, so that it can be used, but it is not part of the set.
+func (s *TimerSet) add(t *Timer) *Timer {
+	s.mu.Lock()
+	t.clock = s.clock
+	s.applyBudget(t)
+	if s.debug && t.origin == "" {
+		t.origin = callerOrigin(2) // The caller of New(), NewContext() etc
+	}
+	target := s.insert(t)
+	if target == nil {
+		s.assignID(t)
+	}
+	s.mu.Unlock()
+	if target != nil {
+		target.addDropped(1)
+	}
+	return t
+}
+
+// Appends the timer to the set if it is within the limits. Otherwise the timer's subtimer is
+// detached, and the set the dropped timer should be counted against is returned. Caller must
+// hold the lock.
+func (s *TimerSet) insert(t *Timer) *TimerSet {
+	if s.dropTo == nil && s.reserve() {
+		s.timers = append(s.timers, t)
+		return nil
+	}
+	target := s.dropTo
+	if target == nil {
+		target = s
+	}
+	if sub := t.sub(); sub != nil {
+		sub.detach(target)
+	}
+	return target
 }
 
 // Retrives the first timer with the provided name
@@ -257,39 +387,129 @@ func (s *TimerSet) Find(name string) *Timer {
 	return nil
 }
 
-// Stops all timers from running, including any child timersets
-// Note: This function locks the entire tree during it's operation
+// Stops all timers from running, including any child timersets. Timers stopped by this
+// function are reported by Leaks(), as the code that started them didn't stop them.
 func (s *TimerSet) StopAllTimers() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	for _, t := range s.timers {
-		if t.duration == 0 && !t.start.IsZero() {
-			t.Stop()
-		}
-		if t.subtimer != nil {
-			t.subtimer.StopAllTimers()
+	timers := append([]*Timer(nil), s.timers...)
+	s.mu.Unlock()
+	for _, t := range timers {
+		// Stop does nothing to timers that are not running
+		t.stop(true)
+		if sub := t.sub(); sub != nil {
+			sub.StopAllTimers()
 		}
 	}
 }
 
+// Locks the timer. Timers that were not created by a TimerSet have no lock, and are only
+// safe to use from a single go routine.
+// Lock order is always TimerSet, then Timer, then the Timer's subtimer TimerSet. Never take
+// a TimerSet lock while holding a Timer lock.
+func (t *Timer) lock() {
+	if t.mu != nil {
+		t.mu.Lock()
+	}
+}
+
+func (t *Timer) unlock() {
+	if t.mu != nil {
+		t.mu.Unlock()
+	}
+}
+
+// Returns a copy of the timer taken under lock. The copy has its own lock, tags, attributes and
+// laps, but still shares the subtimer TimerSet with the original.
+func (t *Timer) copy() Timer {
+	t.lock()
+	defer t.unlock()
+	return t.copyLocked()
+}
+
+// Like copy, but the caller must hold the lock.
+func (t *Timer) copyLocked() Timer {
+	c := *t
+	c.mu = &sync.Mutex{}
+	c.ids = nil // So IDs handed out by the copy don't come from the original's tree
+	if t.tags != nil {
+		c.tags = make([]string, len(t.tags))
+		copy(c.tags, t.tags)
+	}
+	if t.attrs != nil {
+		c.attrs = make([]Attr, len(t.attrs))
+		copy(c.attrs, t.attrs)
+	}
+	if t.laps != nil {
+		c.laps = make([]Lap, len(t.laps))
+		copy(c.laps, t.laps)
+	}
+	if t.events != nil {
+		c.events = make([]Event, len(t.events))
+		copy(c.events, t.events)
+	}
+	if t.counters != nil {
+		c.counters = make([]Counter, len(t.counters))
+		copy(c.counters, t.counters)
+	}
+	return c
+}
+
+// Returns the subtimer TimerSet, if any.
+func (t *Timer) sub() *TimerSet {
+	t.lock()
+	defer t.unlock()
+	return t.subtimer
+}
+
+// Returns true if the timer has started and not stopped. Caller must hold the lock.
+func (t *Timer) running() bool {
+	return !t.start.IsZero() && !t.stopped
+}
+
 // Starts the timer. If the timer had already been started this function does nothing
 func (t *Timer) Start() *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
 	if !t.start.IsZero() {
 		return t
 	}
-	t.start = time.Now()
+	t.start = t.now()
+	t.resumed = t.start
 	return t
 }
 
 // Stops the timer. If the timer has not started or has already been stopped then
 // this function does nothing.
 func (t *Timer) Stop() *Timer {
-	if t.start.IsZero() { // Don't stop if not running
+	return t.stop(false)
+}
+
+// Stops the timer, recording if it was stopped by StopAllTimers.
+func (t *Timer) stop(all bool) *Timer {
+	if t.readOnly() {
 		return t
-	} else if t.duration != 0 { // Don't stop if was running but already stopped
+	}
+	t.lock()
+	if !t.running() { // Don't stop if not running, or already stopped
+		t.unlock()
 		return t
 	}
-	t.duration = time.Since(t.start)
+	now := t.now()
+	t.stopped = true
+	t.stoppedByAll = all
+	t.duration = now.Sub(t.start)
+	if !t.resumed.IsZero() {
+		t.active += now.Sub(t.resumed)
+		t.resumed = time.Time{}
+	}
+	overBudget := t.checkBudget()
+	t.unlock()
+	if overBudget != nil {
+		overBudget(t.copy())
+	}
 	return t
 }
 
@@ -299,17 +519,24 @@ func (t *Timer) nap() *Timer {
 	return t
 }
 
-// Returns how long the timer ran for.
+// Returns how long the timer ran for, in wall clock time (including any time it was paused).
 // If the timer hasn't started, returns 0.
 // If the timer is still running, it returns it's current runtime
 // If the timer has been stopped it returns it's duration.
 func (t *Timer) Duration() time.Duration {
-	if t.duration != 0 {
+	t.lock()
+	defer t.unlock()
+	return t.wallDuration()
+}
+
+// Caller must hold the lock.
+func (t *Timer) wallDuration() time.Duration {
+	if t.stopped {
 		return t.duration
 	} else if t.start.IsZero() {
 		return 0
 	} else {
-		return time.Since(t.start)
+		return t.now().Sub(t.start)
 	}
 }
 
@@ -322,20 +549,29 @@ func (t *Timer) Milliseconds() float64 {
 	return float64(t.Duration().Microseconds()) / float64(1000)
 }
 
-// Returns true if the timer is  running
+// Returns true if the timer is running. A paused timer is still running, see IsPaused()
 func (t *Timer) IsRunning() bool {
-	return !t.start.IsZero() && t.duration == 0
+	t.lock()
+	defer t.unlock()
+	return t.running()
 }
 
 // Tags the timer with a string. Multiple tags are supported.
 // You can do timer.Timers(ctx).New("Test").Tag("tagA").Tag("tagB").Start()
 func (t *Timer) Tag(tag string) *Timer {
+	if t.readOnly() {
+		return t
+	}
+	t.lock()
+	defer t.unlock()
 	t.tags = append(t.tags, tag)
 	return t
 }
 
 // Returns a list of all tags the timer has
 func (t *Timer) Tags() []string {
+	t.lock()
+	defer t.unlock()
 	tags := make([]string, len(t.tags))
 	copy(tags, t.tags)
 	return tags
@@ -343,22 +579,59 @@ func (t *Timer) Tags() []string {
 
 // Returns a _copy_ of the timers under this timer, if any. Returns empty list otherwise.
 func (t *Timer) Children() []Timer {
-	if t.subtimer == nil {
+	sub := t.sub()
+	if sub == nil {
 		return []Timer{}
 	}
-	return t.subtimer.All()
+	return sub.All()
 }
 
-// Returns a string representing the timer's current state
+// Returns a string representing the timer's current state. Timers handed out by All() and
+// Tree() are copies and can be printed freely; to print a live timer that other go routines
+// may be changing, print a copy from one of those instead.
 func (t Timer) String() string {
 	tags := ""
+	if t.dropped > 0 {
+		return t.name
+	}
 	if len(t.tags) > 0 {
 		tags = fmt.Sprintf(" tags:(%s)", strings.Join(t.tags, ","))
 	}
+	if len(t.attrs) > 0 {
+		tags += fmt.Sprintf(" attrs:(%s)", fmtAttrs(t.attrs))
+	}
+	if len(t.laps) > 0 {
+		tags += fmt.Sprintf(" laps:(%s)", fmtLaps(t.laps))
+	}
+	if len(t.events) > 0 {
+		tags += fmt.Sprintf(" events:(%s)", fmtEvents(t.events))
+	}
+	if len(t.counters) > 0 {
+		tags += fmt.Sprintf(" counters:(%s)", fmtCounters(t.counters))
+	}
+	if t.hasChildCounters() {
+		tags += fmt.Sprintf(" totals:(%s)", fmtCounters(t.totals()))
+	}
+	if t.budget > 0 {
+		tags += fmtBudget(&t)
+	}
+	if t.lane > 0 {
+		tags += fmt.Sprintf(" lane:(%d)", t.lane)
+	}
+	if t.err != nil {
+		tags += fmt.Sprintf(" error:(%s)", t.err)
+	}
 	if t.start.IsZero() {
 		return fmt.Sprintf("%s: NotStarted%s", t.name, tags)
-	} else if t.duration == 0 {
+	} else if t.mark {
+		return fmt.Sprintf("%s: Mark%s", t.name, tags)
+	} else if t.isPaused() {
+		return fmt.Sprintf("%s: Paused%s", t.name, tags)
+	} else if !t.stopped {
 		return fmt.Sprintf("%s: Running%s", t.name, tags)
+	} else if t.pauses > 0 {
+		return fmt.Sprintf("%s: %.3fms (active %.3fms)%s", t.name, float64(t.duration)/float64(time.Millisecond),
+			float64(t.active)/float64(time.Millisecond), tags)
 	} else {
 		return fmt.Sprintf("%s: %.3fms%s", t.name, float64(t.duration)/float64(time.Millisecond), tags)
 	}
@@ -368,7 +641,8 @@ func (t Timer) String() string {
 // (Note: The timers have nanosecond resolution, so this function really is "is this the exact same
 // timer?")
 func (t *Timer) Compare(t2 *Timer) bool {
-	if t.name == t2.name && t.start == t2.start && t.duration == t2.duration {
+	a, b := t.copy(), t2.copy()
+	if a.name == b.name && a.start == b.start && a.duration == b.duration {
 		return true
 	} else {
 		return false
@@ -388,35 +662,50 @@ func (t *Timer) Measure(fn func()) {
 	t.Stop()
 }
 
-//  Marshaling type
-type marshalTimer struct {
-	Name     string          `json:"name"`
-	Start    int64           `json:"start"`
-	Duration float64         `json:"duration"`
-	Tags     *[]string       `json:"tags,omitempty"`
-	Children *[]marshalTimer `json:"children,omitempty"`
-}
+//  Marshaling type of the first JSON schema, which is still read, see JSONSchema
 
-// Exports a TimerSet as a list of timers, each timer may have a
-// "children" field, which contains a list of it's children in a
-// tree like structure.
-//
-// To create an output suitable for a waterfall, use
-// json.Marshal(set.GetAll())
+type marshalTimer struct {
+	ID         int               `json:"id"`
+	Parent     int               `json:"parent"`
+	Name       string            `json:"name"`
+	Start      int64             `json:"start"`
+	Duration   float64           `json:"duration"`
+	Tags       *[]string         `json:"tags,omitempty"`
+	Attrs      *[]marshalAttr    `json:"attrs,omitempty"`
+	Error      *string           `json:"error,omitempty"`
+	Laps       *[]marshalLap     `json:"laps,omitempty"`
+	Active     *float64          `json:"active,omitempty"`
+	Pauses     int               `json:"pauses,omitempty"`
+	Dropped    int               `json:"dropped,omitempty"`
+	Mark       bool              `json:"mark,omitempty"`
+	Events     *[]marshalEvent   `json:"events,omitempty"`
+	Counters   *[]marshalCounter `json:"counters,omitempty"`
+	Totals     *[]marshalCounter `json:"totals,omitempty"` // Export only, see Totals
+	Budget     *float64          `json:"budget,omitempty"`
+	OverBudget bool              `json:"overBudget,omitempty"`
+	Lane       int               `json:"lane,omitempty"`
+	Self       *float64          `json:"self,omitempty"` // Export only, see SelfDuration
+	Children   *[]marshalTimer   `json:"children,omitempty"`
+}
+
+// Exports a TimerSet, from a Snapshot, as an object with the "schema" (see JSONSchema), the
+// "time" the snapshot was taken and the list of "timers". Each timer may have a "children" field,
+// which contains a list of it's children in a tree like structure. Times and durations are in
+// nanoseconds, and running timers have their duration so far.
+//  {"schema":2,"time":1644884400002000000,"timers":[
+//      {"id":1,"parent":0,"name":"db","state":"stopped","start":1644884400000000000,"duration":2000000}
+//  ]}
 func (s *TimerSet) MarshalJSON() ([]byte, error) {
-	return json.Marshal(s.toMarshalTimers())
-}
-
-func (s *TimerSet) toMarshalTimers() []marshalTimer {
-	timers := make([]marshalTimer, len(s.timers))
-	for i := 0; i < len(s.timers); i++ {
-		timers[i] = s.timers[i].toMarshalTimer()
-		if s.timers[i].subtimer != nil {
-			list := s.timers[i].subtimer.toMarshalTimers()
-			timers[i].Children = &list
-		}
+	snapshot := s.Snapshot()
+	timers, err := snapshot.toMarshalTimersV2()
+	if err != nil {
+		return nil, err
 	}
-	return timers
+	return json.Marshal(marshalSetV2{
+		Schema: JSONSchema,
+		Time:   snapshot.Clock().Now().UnixNano(),
+		Timers: timers,
+	})
 }
 
 // Given a list of timers, turns it back into a TimerSet
@@ -424,50 +713,81 @@ func (s *TimerSet) toMarshalTimers() []marshalTimer {
 // and will not be able to be associated to a context.
 // You have essentially just imported a block of floating
 // timers
+//
+// Both the current JSON (see MarshalJSON) and the first schema, a list of timers with
+// millisecond times, are read. In the first schema the timers may be nested with "children", or
+// be a flat list where each timer has its "id" and the id of its "parent". Running timers are
+// frozen at the time they were exported, like a Snapshot. When unmarshalling into a new
+// TimerSet the timers keep the IDs they were exported with, if they all have one.
 func (s *TimerSet) UnmarshalJSON(bytes []byte) error {
-	// We are given a list of Timers, hopefully.
+	if s.readOnly() {
+		return nil
+	}
+	timers, err := unmarshalTimers(bytes)
+	if err != nil {
+		return err
+	}
 	s.mu.Lock()
-	err := json.Unmarshal(bytes, &s.timers)
+	s.timers = timers
+	s.dropped, s.droppedId = 0, 0
+	s.collectDropped()
+	ids, parentId := s.ids, s.parentId
 	s.mu.Unlock()
-	return err
+	if ids != nil {
+		s.adopt(ids, parentId)
+	} else {
+		s.importIDs()
+	}
+	return nil
 }
 
+// Exports the timer, and its children, in the same format as a TimerSet's timers (see
+// TimerSet.MarshalJSON), with the "schema" and "time" fields. Timers handed out by All(), Tree()
+// and so on are copies, and can be exported freely. To export a timer that other go routines are
+// using, export a copy from one of those, or its TimerSet.
 func (t Timer) MarshalJSON() ([]byte, error) {
-	return json.Marshal(t.toMarshalTimer())
-}
-func (t *Timer) toMarshalTimer() marshalTimer {
-	var tags *[]string
-	if len(t.tags) > 0 {
-		tags = &t.tags
-	}
-	var epoch = t.start.UnixMilli()
-	if t.start.IsZero() {
-		epoch = 0
-	}
-	return marshalTimer{
-		Name:     t.name,
-		Start:    epoch,
-		Tags:     tags,
-		Duration: t.Milliseconds(),
+	c := t.copy()
+	mt, err := c.toMarshalTimerV2()
+	if err != nil {
+		return nil, err
 	}
+	return json.Marshal(marshalOneTimerV2{
+		Schema:         JSONSchema,
+		Time:           c.now().UnixNano(),
+		marshalTimerV2: mt,
+	})
 }
 
 func (t *Timer) UnmarshalJSON(bytes []byte) error {
-	var mt marshalTimer
-	err := json.Unmarshal(bytes, &mt)
-	if err != nil {
-		return err // Anyone know how I can get here in code coverage? :P
+	if t.readOnly() {
+		return nil
+	}
+	ids, parentId := t.ids, t.parentId
+	if err := t.unmarshalTimer(bytes); err != nil {
+		return err
+	}
+	if ids != nil {
+		adoptTimers([]*Timer{t}, ids, parentId)
+	} else {
+		(&TimerSet{timers: []*Timer{t}}).importIDs()
 	}
-	t.fromMarshaledTimer(mt)
 	return nil
 }
 
-func (t *Timer) fromMarshaledTimer(mt marshalTimer) {
+func (t *Timer) fromMarshaledTimer(mt marshalTimer) error {
+	if t.mu == nil {
+		t.mu = &sync.Mutex{}
+	}
+	t.id, t.parentId = mt.ID, mt.Parent
 	t.name = mt.Name
 	if mt.Start != 0 {
 		t.start = time.UnixMilli(mt.Start)
 	}
-	if mt.Duration == 0 {
+	t.stopped = true
+	t.mark = mt.Mark
+	if mt.Mark {
+		t.duration = 0
+	} else if mt.Duration == 0 {
 		// So here's a thing. If we're marshalling data from a file, if there is a zero
 		// duration, it's probable that the actual millisecond value is zero, and not that
 		// the timer hasn't stopped.
@@ -479,13 +799,55 @@ func (t *Timer) fromMarshaledTimer(mt marshalTimer) {
 	if mt.Tags != nil {
 		t.tags = *mt.Tags
 	}
+	if mt.Active != nil {
+		t.active = time.Duration(*mt.Active * float64(time.Millisecond))
+		t.pauses = mt.Pauses
+		if t.pauses == 0 {
+			t.pauses = 1
+		}
+	}
+	t.dropped = mt.Dropped
+	t.err = errorFromMessage(mt.Error)
+	if mt.Attrs != nil {
+		t.attrs = make([]Attr, len(*mt.Attrs))
+		for i, ma := range *mt.Attrs {
+			a, err := ma.toAttr()
+			if err != nil {
+				return err
+			}
+			t.attrs[i] = a
+		}
+	}
+	if mt.Laps != nil {
+		t.laps = make([]Lap, len(*mt.Laps))
+		for i, ml := range *mt.Laps {
+			t.laps[i] = ml.toLap()
+		}
+	}
+	t.counters = fromMarshalCounters(mt.Counters)
+	if mt.Budget != nil {
+		t.budget = time.Duration(*mt.Budget * float64(time.Millisecond))
+	}
+	t.overBudget = mt.OverBudget
+	t.lane = mt.Lane
+	if mt.Events != nil {
+		t.events = make([]Event, len(*mt.Events))
+		for i, me := range *mt.Events {
+			t.events[i] = me.toEvent(t.start)
+		}
+	}
 	if mt.Children != nil {
 		s := newSet()
+		s.parentId, s.attached = t.id, true
 		t.subtimer = s
 		s.timers = make([]*Timer, len(*mt.Children))
 		for i := 0; i < len(*mt.Children); i++ {
-			s.timers[i] = &Timer{}
-			s.timers[i].fromMarshaledTimer((*mt.Children)[i])
+			s.timers[i] = newTimer("")
+			if err := s.timers[i].fromMarshaledTimer((*mt.Children)[i]); err != nil {
+				return err
+			}
 		}
+		s.collectDropped()
 	}
+	return nil
 }
@@ -232,12 +232,8 @@ func TestTimerMarshal(t *testing.T) {
 	if strings.Contains(string(bytes), "children") {
 		t.Error("Timer with no children exported with children property")
 	}
-	// marshalling is lossy (saved in milliseconds)
-	// so round t1 too.
-	t1.start = time.UnixMilli(t1.start.UnixMilli())
-	// Convert to milliseconds rounded to 3 decimal places (just like the marshall)
-	ms := float64(t1.Duration().Microseconds()) / 1000
-	t1.duration = time.Duration(ms * float64(time.Millisecond))
+	// The round trip is exact, apart from the monotonic clock reading, which isn't exported
+	t1.start = t1.start.Round(0)
 	var t2 Timer
 	err = json.Unmarshal(bytes, &t2)
 	if err != nil {
@@ -0,0 +1,121 @@
+package timers
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// WatchdogOptions configure a watchdog, see TimerSet.Watchdog.
+type WatchdogOptions struct {
+	Threshold time.Duration    // Running timers are reported once they have run for longer than this
+	Interval  time.Duration    // How often to check the timers, defaults to half the Threshold
+	Callback  func(StuckTimer) // Called for each timer found running for longer than the Threshold
+}
+
+// A StuckTimer is a timer found by a watchdog to be running for longer than its threshold.
+type StuckTimer struct {
+	Path  string // Names of the timer's parents and the timer, separated by '/', see FindPath
+	Timer Timer  // A copy of the timer when it was found
+	Stack []byte // The stacks of all go routines when the timer was found, from runtime.Stack
+}
+
+// Starts a watchdog, which checks the running timers in this TimerSet and its children every
+// Interval, and calls Callback for each one that has been running for longer than Threshold,
+// with the stacks of all go routines. Each timer is only reported once. This is for finding work
+// that is stuck, such as a request that never finishes and so never sends its Server-Timing
+// header. Call the returned function to stop the watchdog.
+//  stop := timers.From(ctx).Watchdog(timers.WatchdogOptions{
+//      Threshold: 10 * time.Second,
+//      Callback: func(st timers.StuckTimer) {
+//          log.Printf("%s is stuck:\n%s", st.Path, st.Stack)
+//      },
+//  })
+//  defer stop()
+func (s *TimerSet) Watchdog(opts WatchdogOptions) (stop func()) {
+	if s.readOnly() || opts.Threshold <= 0 || opts.Callback == nil {
+		return func() {}
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = opts.Threshold / 2
+	}
+	w := newWatchdog(s, opts)
+	done := make(chan struct{})
+	go w.run(done)
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+type watchdog struct {
+	set      *TimerSet
+	opts     WatchdogOptions
+	reported map[*Timer]bool // Only used by the watchdog's go routine
+}
+
+func newWatchdog(s *TimerSet, opts WatchdogOptions) *watchdog {
+	return &watchdog{
+		set:      s,
+		opts:     opts,
+		reported: make(map[*Timer]bool),
+	}
+}
+
+func (w *watchdog) run(done chan struct{}) {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// Checks the tree once, calling the callback for any newly stuck timers.
+func (w *watchdog) check() {
+	var stuck []StuckTimer
+	w.scan(w.set, "", &stuck)
+	if len(stuck) == 0 {
+		return
+	}
+	stack := allStacks()
+	for _, st := range stuck {
+		st.Stack = stack
+		w.opts.Callback(st)
+	}
+}
+
+func (w *watchdog) scan(s *TimerSet, prefix string, stuck *[]StuckTimer) {
+	s.mu.Lock()
+	timers := append([]*Timer(nil), s.timers...)
+	s.mu.Unlock()
+	for _, t := range timers {
+		t.lock()
+		isStuck := !w.reported[t] && t.running() && t.wallDuration() > w.opts.Threshold
+		path, sub := prefix+t.name, t.subtimer
+		t.unlock()
+		if isStuck {
+			w.reported[t] = true
+			*stuck = append(*stuck, StuckTimer{Path: path, Timer: t.copy()})
+		}
+		if sub != nil {
+			w.scan(sub, path+"/", stuck)
+		}
+	}
+}
+
+// Returns the stacks of all go routines.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
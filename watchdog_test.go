@@ -0,0 +1,78 @@
+package timers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchdogCheck(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	ctx := NewContext(context.Background())
+	s := From(ctx).SetClock(clock)
+	s.New("done").Start().Stop()
+	s.New("not started")
+	ctx, request := NewContextWithTimer(ctx, "request")
+	request.Start()
+	From(ctx).New("db").Start()
+	clock.Advance(500 * time.Millisecond)
+	From(ctx).New("recent").Start()
+
+	var stuck []StuckTimer
+	w := newWatchdog(s, WatchdogOptions{Threshold: time.Second, Callback: func(st StuckTimer) {
+		stuck = append(stuck, st)
+	}})
+	w.check()
+	if len(stuck) != 0 {
+		t.Fatalf("Watchdog reported timers under the threshold: %v", stuck)
+	}
+	clock.Advance(time.Second)
+	w.check()
+	if len(stuck) != 2 || stuck[0].Path != "request" || stuck[1].Path != "request/db" {
+		t.Fatalf("Watchdog reported %v", stuck)
+	}
+	if stuck[1].Timer.name != "db" || stuck[1].Timer.Duration() != 1500*time.Millisecond {
+		t.Errorf("Watchdog reported the wrong timer: %s", stuck[1].Timer)
+	}
+	if !strings.Contains(string(stuck[0].Stack), "TestWatchdogCheck") {
+		t.Errorf("Stack doesn't include this go routine:\n%s", stuck[0].Stack)
+	}
+
+	stuck = nil
+	clock.Advance(time.Second)
+	w.check()
+	if len(stuck) != 1 || stuck[0].Path != "request/recent" {
+		t.Errorf("Watchdog reported %v, not just the newly stuck timer", stuck)
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	clock := NewManualClock(time.Unix(1644884400, 0))
+	s := newSet().SetClock(clock)
+	s.New("stuck").Start()
+	clock.Advance(time.Minute)
+
+	found := make(chan StuckTimer, 1)
+	stop := s.Watchdog(WatchdogOptions{Threshold: time.Second, Interval: time.Millisecond, Callback: func(st StuckTimer) {
+		found <- st
+	}})
+	defer stop()
+	select {
+	case st := <-found:
+		if st.Path != "stuck" {
+			t.Errorf("Watchdog reported %s", st.Path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watchdog didn't report the stuck timer")
+	}
+	stop()
+}
+
+func TestWatchdogOptions(t *testing.T) {
+	s := newSet()
+	// These don't start a watchdog, but the stop function still works
+	s.Watchdog(WatchdogOptions{Callback: func(StuckTimer) {}})()
+	s.Watchdog(WatchdogOptions{Threshold: time.Second})()
+	noopSet.Watchdog(WatchdogOptions{Threshold: time.Second, Callback: func(StuckTimer) {}})()
+}
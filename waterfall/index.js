@@ -118,19 +118,25 @@ function splitHeader(header) {
     let start = 0;
     let timers = [];
     for (let i = 0; i < header.length; i++) {
-        if (header[i] == '"') {
+        if (header[i] == '\\' && inQuote) {
+            i++; // Skip the escaped character
+        }
+        else if (header[i] == '"') {
             inQuote = inQuote ? false : true;
         }
         else if (header[i] == ',' && !inQuote) {
             timers.push(header.substring(start, i).trim());
-            start = i + 2;
+            start = i + 1;
         }
     }
+    if (header.substring(start).trim() != '') {
+        timers.push(header.substring(start).trim());
+    }
     return timers;
 }
 function headerTimingToTree(header) {
     const timers = splitHeader(header);
-    const re = new RegExp('([^;=]*)=("([^"]*)"|[^";]*)|([^=;]+);', 'g');
+    const re = /([^;=]*)=("((?:[^"\\]|\\.)*)"|[^";]*)|([^=;]+);/g;
     let position = {};
     let startTime;
     let endTime;
@@ -142,7 +148,7 @@ function headerTimingToTree(header) {
             let val;
             let name = match[1] ? match[1] : match[4];
             if (match[3] != undefined) {
-                val = match[3];
+                val = match[3].replace(/\\(.)/g, '$1');
             }
             else if (match[2] != undefined) {
                 val = match[2];
@@ -158,6 +164,17 @@ function headerTimingToTree(header) {
             name: timer['descr'],
             start: parseInt(timer['start']),
             duration: parseFloat(timer['dur']),
+            active: timer['active'] !== undefined ? parseFloat(timer['active']) : undefined,
+            self: timer['self'] !== undefined ? parseFloat(timer['self']) : undefined,
+            error: timer['error'],
+            laps: timer['laps'] !== undefined ? parseLaps(timer['laps']) : undefined,
+            mark: timer['mark'] !== undefined,
+            events: timer['events'] !== undefined ? parseEvents(timer['events']) : undefined,
+            budget: timer['budget'] !== undefined ? parseFloat(timer['budget']) : undefined,
+            overBudget: timer['over_budget'] !== undefined,
+            lane: timer['lane'] !== undefined ? parseInt(timer['lane']) : undefined,
+            counters: parseCounters(timer, 'count.'),
+            totals: parseCounters(timer, 'total.'),
             children: [],
         };
         if (t.id !== undefined) {
@@ -192,6 +209,7 @@ function headerTimingToTree(header) {
             });
         }
     }
+    markCriticalPath(root);
     //console.log(root)
     let tree = {
         nodes: root,
@@ -200,6 +218,69 @@ function headerTimingToTree(header) {
     };
     return tree;
 }
+// The critical path is the timer that finished last, then its child that finished last, and
+// so on down the tree (the same as TimerSet.CriticalPath()). Timers that never started, such
+// as the ones NewContext creates, span their children and are passed through.
+function markCriticalPath(nodes) {
+    var _a;
+    let last;
+    let lastEnd;
+    for (const node of nodes) {
+        const end = (_a = timerSpan(node)) === null || _a === void 0 ? void 0 : _a[1];
+        if (end === undefined)
+            continue;
+        if (!last || end > lastEnd) {
+            last = node;
+            lastEnd = end;
+        }
+    }
+    if (last) {
+        if (!isNaN(last.start))
+            last.critical = true;
+        markCriticalPath(last.children);
+    }
+}
+// Returns the start and end of the timer, or of its children if it never started
+function timerSpan(node) {
+    if (node.mark)
+        return undefined;
+    if (!isNaN(node.start))
+        return [node.start, node.start + node.duration];
+    let span;
+    for (const child of node.children) {
+        const s = timerSpan(child);
+        if (s === undefined)
+            continue;
+        span = span === undefined ? s : [Math.min(span[0], s[0]), Math.max(span[1], s[1])];
+    }
+    return span;
+}
+// Laps are sent as a query string of lap name to lap duration, eg: connect=1.2&send=0.4
+function parseLaps(laps) {
+    let result = [];
+    new URLSearchParams(laps).forEach((value, name) => {
+        result.push({ name: name, duration: parseFloat(value) });
+    });
+    return result;
+}
+// Events are sent as a query string of event name to offset from the start of the timer, eg: first+byte=12.5
+function parseEvents(events) {
+    let result = [];
+    new URLSearchParams(events).forEach((value, name) => {
+        result.push({ name: name, offset: parseFloat(value) });
+    });
+    return result;
+}
+// Counters are sent as parameters with a prefix, eg: count.rows=12000;total.rows=15000
+function parseCounters(timer, prefix) {
+    let result = [];
+    for (const name of Object.keys(timer)) {
+        if (name.startsWith(prefix)) {
+            result.push({ name: name.substring(prefix.length), value: parseInt(timer[name]) });
+        }
+    }
+    return result.length > 0 ? result : undefined;
+}
 function renderTimingsFromHeader(header) {
     const tree = headerTimingToTree(header);
     currentTree = tree;
@@ -241,15 +322,81 @@ function buildTableRow(node, depth, start, end) {
     }
     nameElm.innerText = node.name;
     nameCellElm.appendChild(nameElm);
+    if (node.lane !== undefined) {
+        // Timers run by a Group in different lanes ran in parallel
+        const laneElm = document.createElement('span');
+        laneElm.className = "waterfall-timer-lane";
+        laneElm.innerText = `lane ${node.lane}`;
+        laneElm.title = 'Timers in different lanes ran in parallel';
+        nameCellElm.appendChild(laneElm);
+    }
+    const counters = node.totals !== undefined ? node.totals : node.counters;
+    if (counters !== undefined) {
+        const countersElm = document.createElement('span');
+        countersElm.className = "waterfall-timer-counters";
+        countersElm.innerText = counters.map((c) => `${c.name}=${c.value.toLocaleString()}`).join(' ');
+        if (node.totals !== undefined) {
+            countersElm.title = 'Totals, including the timers under this one';
+        }
+        nameCellElm.appendChild(countersElm);
+    }
     const totalDuration = end - start;
     const percentWidth = Math.round((node.duration / totalDuration) * 100);
     const percentOffset = Math.round(((node.start - start) / totalDuration) * 100);
     barElm.style.left = `${percentOffset}%`;
+    if (node.mark) {
+        // Marks are instants, drawn as a marker on the timeline rather than a bar
+        barElm.classList.add('waterfall-timer-mark');
+        barElm.title = `${node.name} at ${Math.round((node.start - start) * 10) / 10}ms`;
+        timingElm.appendChild(barElm);
+        rowElm.appendChild(nameCellElm);
+        rowElm.appendChild(timingElm);
+        return rowElm;
+    }
     barElm.style.width = `${percentWidth}%`;
     barElm.innerText = `${Math.round(node.duration * 10) / 10}ms`;
-    if (angryColors) {
+    if (node.active !== undefined) {
+        barElm.innerText += ` (active ${Math.round(node.active * 10) / 10}ms)`;
+    }
+    if (node.self !== undefined) {
+        barElm.innerText += ` (self ${Math.round(node.self * 10) / 10}ms)`;
+    }
+    if (node.critical) {
+        rowElm.classList.add('waterfall-row-critical');
+        barElm.title = 'Critical path';
+    }
+    if (node.overBudget) {
+        barElm.classList.add('waterfall-timer-bar-over-budget');
+        barElm.title = `Over budget of ${node.budget}ms`;
+    }
+    if (node.error !== undefined) {
+        barElm.classList.add('waterfall-timer-bar-failed');
+        barElm.title = node.error;
+    }
+    else if (angryColors) {
         barElm.style.backgroundImage = `linear-gradient(hsl(${100 - percentWidth}, 60%, 60%), hsl(${100 - percentWidth}, 60%, 40%))`;
     }
+    if (node.laps !== undefined && node.duration > 0) {
+        let offset = 0;
+        node.laps.forEach((lap, i) => {
+            const lapElm = document.createElement('div');
+            lapElm.className = i % 2 ? "waterfall-timer-lap waterfall-timer-lap-odd" : "waterfall-timer-lap";
+            lapElm.style.left = `${(offset / node.duration) * 100}%`;
+            lapElm.style.width = `${(lap.duration / node.duration) * 100}%`;
+            lapElm.title = `${lap.name}: ${Math.round(lap.duration * 10) / 10}ms`;
+            barElm.appendChild(lapElm);
+            offset += lap.duration;
+        });
+    }
+    if (node.events !== undefined && node.duration > 0) {
+        for (const event of node.events) {
+            const eventElm = document.createElement('div');
+            eventElm.className = "waterfall-timer-event";
+            eventElm.style.left = `${(event.offset / node.duration) * 100}%`;
+            eventElm.title = `${event.name} at ${Math.round(event.offset * 10) / 10}ms`;
+            barElm.appendChild(eventElm);
+        }
+    }
     timingElm.appendChild(barElm);
     rowElm.appendChild(nameCellElm);
     rowElm.appendChild(timingElm);